@@ -0,0 +1,498 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	psql "github.com/ionos-cloud/sdk-go-dbaas-postgres"
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+	"gopkg.in/yaml.v2"
+)
+
+// Configuration of the exporter. Every value has a default, can be set in the config file
+// and can be overridden by the environment variables documented in the chart README.
+type Config struct {
+	Port       string           `yaml:"port"`       // Port to be used for exposing the metrics
+	ApiCycle   int32            `yaml:"apiCycle"`   // Default cycle time in seconds for all collectors
+	Accounts   []AccountConfig  `yaml:"accounts"`   // IONOS accounts to scrape, see AccountConfig
+	Collectors CollectorsConfig `yaml:"collectors"` // Enablement and settings per collector
+	Metrics    []MetricConfig   `yaml:"metrics"`    // Postgres telemetry queries
+}
+
+type CollectorsConfig struct {
+	Datacenter     DatacenterCollectorConfig   `yaml:"datacenter"`
+	ContractLimits CollectorConfig             `yaml:"contractLimits"`
+	S3             S3CollectorConfig           `yaml:"s3"`
+	Postgres       CollectorConfig             `yaml:"postgres"`
+	Storage        CollectorConfig             `yaml:"storage"`
+	K8s            CollectorConfig             `yaml:"k8s"`
+	Connectivity   ConnectivityCollectorConfig `yaml:"connectivity"`
+	Requests       RequestsCollectorConfig     `yaml:"requests"`
+}
+
+type CollectorConfig struct {
+	Enabled bool  `yaml:"enabled"`
+	Cycle   int32 `yaml:"cycle"`   // Cycle time in seconds, apiCycle if not set
+	Jitter  int32 `yaml:"jitter"`  // Maximum random delay in seconds added to every cycle
+	Timeout int32 `yaml:"timeout"` // Maximum duration of one collection run in seconds, unlimited if not set
+}
+
+type DatacenterCollectorConfig struct {
+	CollectorConfig `yaml:",inline"`
+	NetworkTopology bool `yaml:"networkTopology"` // Collect LANs, NICs and firewall rules, one additional API call per server
+}
+
+type ConnectivityCollectorConfig struct {
+	CollectorConfig `yaml:",inline"`
+	VPNLocations    []string `yaml:"vpnLocations"` // Locations of the VPN Gateway API to query, e.g. de-fra
+}
+
+type RequestsCollectorConfig struct {
+	CollectorConfig `yaml:",inline"`
+	WindowString    string        `yaml:"window"` // Sliding window in which provisioning requests are counted, e.g. 1h
+	Window          time.Duration `yaml:"-"`
+}
+
+type S3CollectorConfig struct {
+	CollectorConfig `yaml:",inline"`
+	Endpoints       []S3EndpointConfig `yaml:"endpoints"`
+	TagLabels       []S3TagLabelConfig `yaml:"tagLabels"` // Labels of the S3 metrics which are filled from bucket tags
+	Checkpoint      S3CheckpointConfig `yaml:"checkpoint"`
+	TopN            S3TopNConfig       `yaml:"topN"`
+	Logs            S3LogsConfig       `yaml:"logs"`
+	Usage           S3UsageConfig      `yaml:"usage"`
+	Posture         bool               `yaml:"posture"` // Check the security relevant settings of the buckets
+	Multipart       S3MultipartConfig  `yaml:"multipart"`
+}
+
+// Incomplete multipart uploads of the buckets
+type S3MultipartConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	SampleSize int  `yaml:"sampleSize"` // Maximum number of uploads per bucket whose parts are listed to estimate the size
+}
+
+// Size and number of objects of the buckets, every bucket is listed completely, so it is sampled less often
+type S3UsageConfig struct {
+	Enabled     bool  `yaml:"enabled"`
+	Concurrency int   `yaml:"concurrency"` // Number of buckets of an account which are listed at the same time
+	Interval    int32 `yaml:"interval"`    // Minimum time in seconds between two listings of the same bucket
+}
+
+// Where the access logs of the buckets are read from. The location of a bucket is taken from logTargets,
+// else from the logging configuration of the bucket and else the logs are expected in the bucket itself below prefix.
+type S3LogsConfig struct {
+	Prefix        string                       `yaml:"prefix"`        // Prefix of the logs inside the bucket itself
+	BucketLogging bool                         `yaml:"bucketLogging"` // Read the logging configuration of the buckets with GetBucketLogging
+	Targets       map[string]S3LogTargetConfig `yaml:"targets"`       // Log location keyed by the name of the source bucket
+	MaxObjects    int                          `yaml:"maxObjects"`    // Log objects processed per bucket and cycle, 0 for no limit
+}
+
+type S3LogTargetConfig struct {
+	Bucket string `yaml:"bucket"` // Bucket the logs are written to, on the same endpoint as the source bucket
+	Prefix string `yaml:"prefix"`
+}
+
+// Breakdowns of the access logs by requester, remote network and key prefix.
+// Only the entries with the most bytes sent are exported, the rest is summed up as other.
+type S3TopNConfig struct {
+	Limit            int `yaml:"limit"`            // Number of entries per breakdown and bucket, 0 disables the breakdowns
+	KeyPrefixDepth   int `yaml:"keyPrefixDepth"`   // Number of leading path segments of the object keys used as prefix
+	IPv4PrefixLength int `yaml:"ipv4PrefixLength"` // Remote IPs are grouped into networks of this length
+	IPv6PrefixLength int `yaml:"ipv6PrefixLength"`
+}
+
+// A label of the S3 metrics which is filled from a bucket tag
+type S3TagLabelConfig struct {
+	Label         string   `yaml:"label"`
+	Tag           string   `yaml:"tag"`           // Key of the bucket tag
+	Default       string   `yaml:"default"`       // Value if the bucket has no such tag
+	AllowedValues []string `yaml:"allowedValues"` // If set, all other tag values are exported as other
+}
+
+// Where the last processed log object of every bucket is stored, either in a local file or in an S3 object.
+// Without a store all log objects are processed again after a restart.
+type S3CheckpointConfig struct {
+	File     string `yaml:"file"`
+	Endpoint string `yaml:"endpoint"` // Name of the endpoint of the bucket, its credentials are used
+	Bucket   string `yaml:"bucket"`
+	Key      string `yaml:"key"`
+}
+
+// An S3 endpoint, e.g. an IONOS S3 region or a MinIO instance
+type S3EndpointConfig struct {
+	Name         string `yaml:"name"` // Value of the endpoint label of the bucket metrics, the region if not set
+	Region       string `yaml:"region"`
+	Endpoint     string `yaml:"endpoint"`
+	AccessKeyEnv string `yaml:"accessKeyEnv"` // Environment variables holding the credentials of this endpoint,
+	SecretKeyEnv string `yaml:"secretKeyEnv"` // the S3 credentials of the account are used if not set
+	PathStyle    bool   `yaml:"pathStyle"`    // Address buckets by path instead of subdomain, needed for MinIO
+	CABundle     string `yaml:"caBundle"`     // Path to a PEM file with additional CA certificates
+}
+
+// An IONOS account (contract) to scrape. Credentials are never stored in the config file,
+// the *Env fields reference the environment variables holding them.
+// Empty references fall back to the default variables of the single account setup.
+type AccountConfig struct {
+	Name           string `yaml:"name"`
+	TokenEnv       string `yaml:"tokenEnv"`
+	UsernameEnv    string `yaml:"usernameEnv"`
+	PasswordEnv    string `yaml:"passwordEnv"`
+	S3AccessKeyEnv string `yaml:"s3AccessKeyEnv"`
+	S3SecretKeyEnv string `yaml:"s3SecretKeyEnv"`
+}
+
+type MetricConfig struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type"`
+}
+
+// Valid Prometheus label names, names starting with __ are reserved
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func defaultConfig() *Config {
+	return &Config{
+		Port:     "9100",
+		ApiCycle: 200,
+		Collectors: CollectorsConfig{
+			Datacenter:     DatacenterCollectorConfig{CollectorConfig: CollectorConfig{Enabled: true}},
+			ContractLimits: CollectorConfig{Enabled: true},
+			S3: S3CollectorConfig{
+				Endpoints: []S3EndpointConfig{
+					{Region: "eu-central-2", Endpoint: "https://s3-eu-central-2.ionoscloud.com"},
+					{Region: "de", Endpoint: "https://s3-eu-central-1.ionoscloud.com"},
+				},
+				TagLabels: []S3TagLabelConfig{
+					{Label: "enviroment", Tag: "Enviroment"},
+					{Label: "namespace", Tag: "Namespace"},
+					{Label: "tenant", Tag: "Tenant"},
+				},
+				TopN:      S3TopNConfig{Limit: 10, KeyPrefixDepth: 1, IPv4PrefixLength: 24, IPv6PrefixLength: 48},
+				Logs:      S3LogsConfig{Prefix: "logs/", BucketLogging: true, MaxObjects: 1000},
+				Posture:   true,
+				Multipart: S3MultipartConfig{Enabled: true, SampleSize: 10},
+				Usage:     S3UsageConfig{Concurrency: 2, Interval: 3600},
+			},
+			Connectivity: ConnectivityCollectorConfig{VPNLocations: []string{"de-fra", "de-txl"}},
+			Requests:     RequestsCollectorConfig{WindowString: "1h"},
+		},
+	}
+}
+
+/*
+Loads the config file, applies the environment variable overrides and validates the result.
+A missing config file is not an error, the defaults and environment variables are used instead.
+
+Parameters:
+  - filename: path to the config file
+
+Returns:
+  - *Config: the validated configuration, or an error describing every invalid setting
+*/
+func LoadConfig(filename string) (*Config, error) {
+	config := defaultConfig()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		fmt.Printf("Config file %s not found, using defaults and environment variables\n", filename)
+	} else if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse config file %s: %v", filename, err)
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return config, nil
+}
+
+// Environment variables take precedence over the values of the config file
+func (config *Config) applyEnv() error {
+	var err error
+	collectors := &config.Collectors
+	config.Port = GetEnv("IONOS_EXPORTER_APPLICATION_CONTAINER_PORT", config.Port)
+	if config.ApiCycle, err = GetInt32Env("IONOS_EXPORTER_API_CYCLE", config.ApiCycle); err != nil {
+		return err
+	}
+	for name, collector := range map[string]*CollectorConfig{
+		"DATACENTER":      &collectors.Datacenter.CollectorConfig,
+		"CONTRACT_LIMITS": &collectors.ContractLimits,
+		"S3":              &collectors.S3.CollectorConfig,
+		"POSTGRES":        &collectors.Postgres,
+		"STORAGE":         &collectors.Storage,
+		"K8S":             &collectors.K8s,
+		"CONNECTIVITY":    &collectors.Connectivity.CollectorConfig,
+		"REQUESTS":        &collectors.Requests.CollectorConfig,
+	} {
+		if collector.Enabled, err = GetBoolEnv("IONOS_EXPORTER_"+name+"_ENABLED", collector.Enabled); err != nil {
+			return err
+		}
+		if collector.Cycle, err = GetInt32Env("IONOS_EXPORTER_"+name+"_CYCLE", collector.Cycle); err != nil {
+			return err
+		}
+		if collector.Jitter, err = GetInt32Env("IONOS_EXPORTER_"+name+"_JITTER", collector.Jitter); err != nil {
+			return err
+		}
+		if collector.Timeout, err = GetInt32Env("IONOS_EXPORTER_"+name+"_TIMEOUT", collector.Timeout); err != nil {
+			return err
+		}
+	}
+	if collectors.Datacenter.NetworkTopology, err = GetBoolEnv("IONOS_EXPORTER_NETWORK_ENABLED", collectors.Datacenter.NetworkTopology); err != nil {
+		return err
+	}
+	if locations := os.Getenv("IONOS_EXPORTER_VPN_LOCATIONS"); locations != "" {
+		collectors.Connectivity.VPNLocations = nil
+		for _, location := range strings.Split(locations, ",") {
+			if location = strings.TrimSpace(location); location != "" {
+				collectors.Connectivity.VPNLocations = append(collectors.Connectivity.VPNLocations, location)
+			}
+		}
+	}
+	collectors.Requests.WindowString = GetEnv("IONOS_EXPORTER_REQUESTS_WINDOW", collectors.Requests.WindowString)
+	if len(config.Accounts) == 0 {
+		config.Accounts = []AccountConfig{{Name: os.Getenv("IONOS_ACCOUNT")}}
+	}
+	return nil
+}
+
+// Checks every setting and fills the derived values, all problems are reported at once
+func (config *Config) validate() error {
+	var errs []error
+	collectors := &config.Collectors
+
+	if config.Port == "" {
+		errs = append(errs, fmt.Errorf("port: must not be empty"))
+	}
+	if config.ApiCycle <= 0 {
+		errs = append(errs, fmt.Errorf("apiCycle: must be greater than 0, got %d", config.ApiCycle))
+	}
+
+	names := make(map[string]bool)
+	for i, account := range config.Accounts {
+		if account.Name == "" && len(config.Accounts) > 1 {
+			errs = append(errs, fmt.Errorf("accounts[%d].name: must not be empty if several accounts are configured", i))
+		}
+		if names[account.Name] {
+			errs = append(errs, fmt.Errorf("accounts[%d].name: account %q is configured more than once", i, account.Name))
+		}
+		names[account.Name] = true
+	}
+
+	for name, collector := range map[string]*CollectorConfig{
+		"datacenter":     &collectors.Datacenter.CollectorConfig,
+		"contractLimits": &collectors.ContractLimits,
+		"s3":             &collectors.S3.CollectorConfig,
+		"postgres":       &collectors.Postgres,
+		"storage":        &collectors.Storage,
+		"k8s":            &collectors.K8s,
+		"connectivity":   &collectors.Connectivity.CollectorConfig,
+		"requests":       &collectors.Requests.CollectorConfig,
+	} {
+		if collector.Cycle == 0 {
+			collector.Cycle = config.ApiCycle
+		}
+		// The settings of disabled collectors are not used, so they are not checked
+		if !collector.Enabled {
+			continue
+		}
+		if collector.Cycle < 0 {
+			errs = append(errs, fmt.Errorf("collectors.%s.cycle: must not be negative, got %d", name, collector.Cycle))
+		}
+		if collector.Jitter < 0 {
+			errs = append(errs, fmt.Errorf("collectors.%s.jitter: must not be negative, got %d", name, collector.Jitter))
+		}
+		if collector.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("collectors.%s.timeout: must not be negative, got %d", name, collector.Timeout))
+		}
+	}
+
+	if collectors.S3.Enabled {
+		if len(collectors.S3.Endpoints) == 0 {
+			errs = append(errs, fmt.Errorf("collectors.s3.endpoints: at least one endpoint is required"))
+		}
+		endpointNames := make(map[string]bool)
+		for i := range collectors.S3.Endpoints {
+			endpoint := &collectors.S3.Endpoints[i]
+			if endpoint.Region == "" {
+				errs = append(errs, fmt.Errorf("collectors.s3.endpoints[%d].region: must not be empty", i))
+			}
+			if endpoint.Name == "" {
+				endpoint.Name = endpoint.Region
+			}
+			if endpointNames[endpoint.Name] {
+				errs = append(errs, fmt.Errorf("collectors.s3.endpoints[%d].name: endpoint %q is configured more than once", i, endpoint.Name))
+			}
+			endpointNames[endpoint.Name] = true
+			if parsed, err := url.Parse(endpoint.Endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+				errs = append(errs, fmt.Errorf("collectors.s3.endpoints[%d].endpoint: %q is not an absolute URL", i, endpoint.Endpoint))
+			}
+			if (endpoint.AccessKeyEnv == "") != (endpoint.SecretKeyEnv == "") {
+				errs = append(errs, fmt.Errorf("collectors.s3.endpoints[%d]: accessKeyEnv and secretKeyEnv must be set together", i))
+			}
+			if endpoint.CABundle != "" {
+				if _, err := os.Stat(endpoint.CABundle); err != nil {
+					errs = append(errs, fmt.Errorf("collectors.s3.endpoints[%d].caBundle: %v", i, err))
+				}
+			}
+		}
+		checkpoint := &collectors.S3.Checkpoint
+		if checkpoint.File != "" && checkpoint.Bucket != "" {
+			errs = append(errs, fmt.Errorf("collectors.s3.checkpoint: only one of file and bucket can be set"))
+		}
+		if checkpoint.Bucket != "" {
+			if !endpointNames[checkpoint.Endpoint] {
+				errs = append(errs, fmt.Errorf("collectors.s3.checkpoint.endpoint: %q is not a configured endpoint", checkpoint.Endpoint))
+			}
+			if checkpoint.Key == "" {
+				checkpoint.Key = "ionos-exporter/checkpoints.json"
+			}
+		}
+		topN := collectors.S3.TopN
+		if topN.Limit < 0 {
+			errs = append(errs, fmt.Errorf("collectors.s3.topN.limit: must not be negative, got %d", topN.Limit))
+		}
+		if topN.KeyPrefixDepth < 1 {
+			errs = append(errs, fmt.Errorf("collectors.s3.topN.keyPrefixDepth: must be at least 1, got %d", topN.KeyPrefixDepth))
+		}
+		if topN.IPv4PrefixLength < 0 || topN.IPv4PrefixLength > 32 {
+			errs = append(errs, fmt.Errorf("collectors.s3.topN.ipv4PrefixLength: must be between 0 and 32, got %d", topN.IPv4PrefixLength))
+		}
+		if topN.IPv6PrefixLength < 0 || topN.IPv6PrefixLength > 128 {
+			errs = append(errs, fmt.Errorf("collectors.s3.topN.ipv6PrefixLength: must be between 0 and 128, got %d", topN.IPv6PrefixLength))
+		}
+		if collectors.S3.Usage.Concurrency < 1 {
+			errs = append(errs, fmt.Errorf("collectors.s3.usage.concurrency: must be at least 1, got %d", collectors.S3.Usage.Concurrency))
+		}
+		if collectors.S3.Usage.Interval < 0 {
+			errs = append(errs, fmt.Errorf("collectors.s3.usage.interval: must not be negative, got %d", collectors.S3.Usage.Interval))
+		}
+		if collectors.S3.Multipart.SampleSize < 0 {
+			errs = append(errs, fmt.Errorf("collectors.s3.multipart.sampleSize: must not be negative, got %d", collectors.S3.Multipart.SampleSize))
+		}
+		if collectors.S3.Logs.MaxObjects < 0 {
+			errs = append(errs, fmt.Errorf("collectors.s3.logs.maxObjects: must not be negative"))
+		}
+		for source, target := range collectors.S3.Logs.Targets {
+			if target.Bucket == "" {
+				errs = append(errs, fmt.Errorf("collectors.s3.logs.targets.%s.bucket: must not be empty", source))
+			}
+		}
+	}
+
+	// The S3 collector is registered even if it is disabled, so its labels are always checked
+	labelNames := append(append([]string{}, s3BucketLabels...), s3InfoLabels...)
+	for i, tagLabel := range collectors.S3.TagLabels {
+		if !labelNameRe.MatchString(tagLabel.Label) || strings.HasPrefix(tagLabel.Label, "__") {
+			errs = append(errs, fmt.Errorf("collectors.s3.tagLabels[%d].label: %q is not a valid label name", i, tagLabel.Label))
+		}
+		if contains(labelNames, tagLabel.Label) {
+			errs = append(errs, fmt.Errorf("collectors.s3.tagLabels[%d].label: %q is already used", i, tagLabel.Label))
+		}
+		labelNames = append(labelNames, tagLabel.Label)
+		if tagLabel.Tag == "" {
+			errs = append(errs, fmt.Errorf("collectors.s3.tagLabels[%d].tag: must not be empty", i))
+		}
+	}
+
+	if collectors.Connectivity.Enabled {
+		for i, location := range collectors.Connectivity.VPNLocations {
+			collectors.Connectivity.VPNLocations[i] = strings.TrimSpace(location)
+			if collectors.Connectivity.VPNLocations[i] == "" {
+				errs = append(errs, fmt.Errorf("collectors.connectivity.vpnLocations[%d]: must not be empty", i))
+			}
+		}
+	}
+
+	if collectors.Requests.Enabled {
+		window, err := time.ParseDuration(collectors.Requests.WindowString)
+		if err != nil || window <= 0 {
+			errs = append(errs, fmt.Errorf("collectors.requests.window: %q is not a positive duration", collectors.Requests.WindowString))
+		}
+		collectors.Requests.Window = window
+	}
+
+	for i, metric := range config.Metrics {
+		if metric.Name == "" {
+			errs = append(errs, fmt.Errorf("metrics[%d].name: must not be empty", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Value of the label for a bucket with the given tags
+func (tagLabel S3TagLabelConfig) Value(tags map[string]string) string {
+	value := tags[tagLabel.Tag]
+	if value == "" {
+		return tagLabel.Default
+	}
+	if len(tagLabel.AllowedValues) > 0 && !contains(tagLabel.AllowedValues, value) {
+		return "other"
+	}
+	return value
+}
+
+func getEnvReference(reference string, fallback string) string {
+	if reference == "" {
+		reference = fallback
+	}
+	return os.Getenv(reference)
+}
+
+func (account AccountConfig) Token() string {
+	return getEnvReference(account.TokenEnv, ionoscloud.IonosTokenEnvVar)
+}
+
+func (account AccountConfig) S3AccessKey() string {
+	return getEnvReference(account.S3AccessKeyEnv, "AWS_ACCESS_KEY_ID")
+}
+
+func (account AccountConfig) S3SecretKey() string {
+	return getEnvReference(account.S3SecretKeyEnv, "AWS_SECRET_ACCESS_KEY")
+}
+
+// S3 credentials for the endpoint, the credentials of the endpoint take precedence over the ones of the account
+func (endpoint S3EndpointConfig) Credentials(account AccountConfig) (accessKey string, secretKey string) {
+	if endpoint.AccessKeyEnv != "" {
+		return os.Getenv(endpoint.AccessKeyEnv), os.Getenv(endpoint.SecretKeyEnv)
+	}
+	return account.S3AccessKey(), account.S3SecretKey()
+}
+
+// Configuration for the ionoscloud API Client with the credentials of the account
+func (account AccountConfig) IonosConfiguration() *ionoscloud.Configuration {
+	return ionoscloud.NewConfiguration(
+		getEnvReference(account.UsernameEnv, ionoscloud.IonosUsernameEnvVar),
+		getEnvReference(account.PasswordEnv, ionoscloud.IonosPasswordEnvVar),
+		account.Token(),
+		os.Getenv(ionoscloud.IonosApiUrlEnvVar),
+	)
+}
+
+// Configuration for the DBaaS Postgres API Client with the credentials of the account
+func (account AccountConfig) PostgresConfiguration() *psql.Configuration {
+	return psql.NewConfiguration(
+		getEnvReference(account.UsernameEnv, psql.IonosUsernameEnvVar),
+		getEnvReference(account.PasswordEnv, psql.IonosPasswordEnvVar),
+		account.Token(),
+		os.Getenv(psql.IonosApiUrlEnvVar),
+	)
+}
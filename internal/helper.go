@@ -1,8 +1,8 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
@@ -12,19 +12,8 @@ import (
 	aws "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"gopkg.in/yaml.v2"
 )
 
-type Config struct {
-	Metrics []MetricConfig `yaml:"metrics"`
-}
-
-type MetricConfig struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Type        string `yaml:"type"`
-}
-
 func GetEnv(key string, fallback string) string {
 	value, ok := os.LookupEnv(key)
 	if !ok {
@@ -60,11 +49,30 @@ func GetBoolEnv(key string, fallback bool) (bool, error) {
 	}
 }
 
-func GetHeadBucket(client *s3.S3, bucketName string) error {
+func GetInt32Env(key string, fallback int32) (int32, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		fmt.Printf("%s not set, returning %d\n", key, fallback)
+		return fallback, nil
+	} else {
+		if value == "" {
+			fmt.Printf("%s set but empty, returning %d\n", key, fallback)
+			return fallback, nil
+		} else {
+			intValue, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return fallback, fmt.Errorf("Invalid value for %s=%q (expected integer): %v", key, value, err)
+			}
+			return int32(intValue), nil
+		}
+	}
+}
+
+func GetHeadBucket(ctx context.Context, client *s3.S3, bucketName string) error {
 	input := &s3.HeadBucketInput{
 		Bucket: aws.String(bucketName),
 	}
-	_, err := client.HeadBucket(input)
+	_, err := client.HeadBucketWithContext(ctx, input)
 	if err != nil {
 		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == 403 {
 			log.Printf("Skipping bucket %s due to Forbidden error: %v\n", bucketName, err)
@@ -77,21 +85,6 @@ func GetHeadBucket(client *s3.S3, bucketName string) error {
 	return nil
 }
 
-func LoadConfig(filename string) (*Config, error) {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, err
-	}
-
-	return &config, nil
-}
-
 var toSnakeRe = regexp.MustCompile("([a-z0-9])([A-Z])")
 
 func ToSnake(s string) string {
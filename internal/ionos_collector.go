@@ -1,7 +1,8 @@
 package internal
 
 import (
-	"os"
+	"strconv"
+	"strings"
 	"sync"
 
 	//"time"
@@ -30,8 +31,39 @@ type ionosCollector struct {
 	dcDCNATMetric     *prometheus.GaugeVec
 	dcNLBRulesMetric  *prometheus.GaugeVec
 	dcALBRulesMetric  *prometheus.GaugeVec
-	dcTotalIpsMetric  prometheus.Gauge
-	apiFailuresMetric prometheus.Counter
+	dcTotalIpsMetric  *prometheus.GaugeVec
+	dcInfoMetric      *prometheus.GaugeVec
+	apiFailuresMetric *prometheus.CounterVec
+
+	nlbRulesMetric       *prometheus.GaugeVec
+	nlbRuleTargetsMetric *prometheus.GaugeVec
+	albRulesMetric       *prometheus.GaugeVec
+	albRuleTargetsMetric *prometheus.GaugeVec
+
+	targetGroupTargetsMetric            *prometheus.GaugeVec
+	targetGroupHealthCheckTargetsMetric *prometheus.GaugeVec
+	targetGroupCheckIntervalMetric      *prometheus.GaugeVec
+	targetGroupCheckTimeoutMetric       *prometheus.GaugeVec
+	targetGroupRetriesMetric            *prometheus.GaugeVec
+	targetGroupHttpHealthCheckMetric    *prometheus.GaugeVec
+	targetGroupReferencesMetric         *prometheus.GaugeVec
+	albRuleTargetGroupTargetsMetric     *prometheus.GaugeVec
+
+	ipBlockSizeMetric      *prometheus.GaugeVec
+	ipBlockUsedIpsMetric   *prometheus.GaugeVec
+	ipBlockUnusedIpsMetric *prometheus.GaugeVec
+	totalUnusedIpsMetric   *prometheus.GaugeVec
+
+	natPublicIpsMetric *prometheus.GaugeVec
+	natSNATRulesMetric *prometheus.GaugeVec
+	natLansMetric      *prometheus.GaugeVec
+	natLanInfoMetric   *prometheus.GaugeVec
+	natStateMetric     *prometheus.GaugeVec
+
+	serverInfoMetric    *prometheus.GaugeVec
+	serverCoresMetric   *prometheus.GaugeVec
+	serverRamMetric     *prometheus.GaugeVec
+	serverVmStateMetric *prometheus.GaugeVec
 }
 
 // You must create a constructor for you collector that
@@ -42,15 +74,15 @@ func NewIonosCollector(m *sync.RWMutex) *ionosCollector {
 		coresMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dc_cores_amount",
 			Help: "Shows the number of currently active cores in an IONOS datacenter",
-		}, []string{"datacenter"}),
+		}, []string{"account", "datacenter"}),
 		ramMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dc_ram_gb",
 			Help: "Shows the number of currently active RAM in an IONOS datacenter",
-		}, []string{"datacenter"}),
+		}, []string{"account", "datacenter"}),
 		serverMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dc_server_amount",
 			Help: "Shows the number of currently active servers in an IONOS datacenter",
-		}, []string{"datacenter"}),
+		}, []string{"account", "datacenter"}),
 		dcCoresMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_total_cores_amount",
 			Help: "Shows the number of currently active cores of an IONOS account",
@@ -70,15 +102,15 @@ func NewIonosCollector(m *sync.RWMutex) *ionosCollector {
 		nlbsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_networkloadbalancer_amount",
 			Help: "Shows the number of active Network Loadbalancers in an IONOS datacenter",
-		}, []string{"datacenter", "nlb_name", "nlb_rules_name"}),
+		}, []string{"account", "datacenter"}),
 		albsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_applicationloadbalancer_amount",
 			Help: "Shows the number of active Application Loadbalancers in an IONOS datacenter",
-		}, []string{"datacenter", "alb_name", "alb_rules_name"}),
+		}, []string{"account", "datacenter"}),
 		natsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_nat_gateways_amount",
 			Help: "Shows the number of NAT Gateways in an IONOS datacenter",
-		}, []string{"datacenter"}),
+		}, []string{"account", "datacenter"}),
 		dcDCNLBMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_total_networkloadbalancer_amount",
 			Help: "Shows the total number of Network Loadbalancers in IONOS Account",
@@ -94,19 +126,123 @@ func NewIonosCollector(m *sync.RWMutex) *ionosCollector {
 		dcNLBRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_total_number_of_nlb_rules",
 			Help: "Shows the total number of NLB Rules in IONOS Account",
-		}, []string{"nlb_rules"}),
+		}, []string{"account", "nlb_rules"}),
 		dcALBRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_total_nmumber_of_alb_rules",
 			Help: "Shows the total number of ALB Rules in IONOS Account",
-		}, []string{"alb_rules"}),
-		dcTotalIpsMetric: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, []string{"account", "alb_rules"}),
+		dcTotalIpsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_total_number_of_ips",
 			Help: "Shows the number of Ips in a IONOS",
-		}),
-		apiFailuresMetric: prometheus.NewCounter(prometheus.CounterOpts{
+		}, []string{"account"}),
+		dcInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_datacenter_info",
+			Help: "Shows the UUID and location of an IONOS datacenter, the value is always 1",
+		}, []string{"account", "datacenter", "datacenter_id", "location"}),
+		apiFailuresMetric: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "ionos_api_failures_total",
 			Help: "Total number of failed API calls",
-		}),
+		}, []string{"account"}),
+		nlbRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_networkloadbalancer_forwarding_rules_amount",
+			Help: "Shows the number of forwarding rules of a Network Loadbalancer",
+		}, []string{"account", "datacenter", "nlb_id", "nlb_name", "listener_ips", "state"}),
+		nlbRuleTargetsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_networkloadbalancer_rule_targets_amount",
+			Help: "Shows the number of targets of a Network Loadbalancer forwarding rule",
+		}, []string{"account", "datacenter", "nlb_id", "nlb_name", "rule_id", "rule_name", "protocol", "listener_port"}),
+		albRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_applicationloadbalancer_forwarding_rules_amount",
+			Help: "Shows the number of forwarding rules of an Application Loadbalancer",
+		}, []string{"account", "datacenter", "alb_id", "alb_name", "listener_ips", "state"}),
+		albRuleTargetsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_applicationloadbalancer_rule_targets_amount",
+			Help: "Shows the number of target groups referenced by an Application Loadbalancer forwarding rule",
+		}, []string{"account", "datacenter", "alb_id", "alb_name", "rule_id", "rule_name", "protocol", "listener_port"}),
+		targetGroupTargetsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_targets_amount",
+			Help: "Shows the number of targets in an ALB target group",
+		}, []string{"account", "targetgroup_id", "targetgroup", "algorithm", "protocol"}),
+		targetGroupHealthCheckTargetsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_health_check_enabled_targets_amount",
+			Help: "Shows the number of targets with enabled health check in an ALB target group",
+		}, []string{"account", "targetgroup_id", "targetgroup"}),
+		targetGroupCheckIntervalMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_health_check_interval_ms",
+			Help: "Shows the interval between two health checks of an ALB target group in milliseconds",
+		}, []string{"account", "targetgroup_id", "targetgroup"}),
+		targetGroupCheckTimeoutMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_health_check_timeout_ms",
+			Help: "Shows the timeout of a health check of an ALB target group in milliseconds",
+		}, []string{"account", "targetgroup_id", "targetgroup"}),
+		targetGroupRetriesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_health_check_retries",
+			Help: "Shows the number of health check retries before a target of an ALB target group is considered unhealthy",
+		}, []string{"account", "targetgroup_id", "targetgroup"}),
+		targetGroupHttpHealthCheckMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_http_health_check_info",
+			Help: "Shows the HTTP health check settings of an ALB target group, the value is always 1",
+		}, []string{"account", "targetgroup_id", "targetgroup", "path", "method", "match_type", "response"}),
+		targetGroupReferencesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_targetgroup_forwarding_rule_references_amount",
+			Help: "Shows the number of ALB forwarding rules which reference an ALB target group",
+		}, []string{"account", "targetgroup_id", "targetgroup"}),
+		albRuleTargetGroupTargetsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_applicationloadbalancer_rule_targetgroup_targets_amount",
+			Help: "Shows the number of targets in a target group referenced by an Application Loadbalancer forwarding rule",
+		}, []string{"account", "datacenter", "alb_id", "alb_name", "rule_id", "rule_name", "targetgroup_id", "targetgroup"}),
+		ipBlockSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_ipblock_size",
+			Help: "Shows the number of reserved IPs in an IP block",
+		}, []string{"account", "ipblock_id", "ipblock", "location"}),
+		ipBlockUsedIpsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_ipblock_used_ips_amount",
+			Help: "Shows the number of reserved IPs of an IP block which are assigned to a NIC, loadbalancer, NAT gateway or kubernetes node",
+		}, []string{"account", "ipblock_id", "ipblock", "location", "consumer"}),
+		ipBlockUnusedIpsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_ipblock_unused_ips_amount",
+			Help: "Shows the number of reserved IPs of an IP block which are not assigned to any resource",
+		}, []string{"account", "ipblock_id", "ipblock", "location"}),
+		totalUnusedIpsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_total_number_of_unused_ips",
+			Help: "Shows the number of reserved Ips in IONOS Account which are not assigned to any resource",
+		}, []string{"account"}),
+		natPublicIpsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nat_gateway_public_ips_amount",
+			Help: "Shows the number of public IPs of a NAT Gateway",
+		}, []string{"account", "datacenter", "nat_id", "nat_name", "public_ips"}),
+		natSNATRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nat_gateway_snat_rules_amount",
+			Help: "Shows the number of SNAT rules of a NAT Gateway",
+		}, []string{"account", "datacenter", "nat_id", "nat_name"}),
+		natLansMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nat_gateway_lans_amount",
+			Help: "Shows the number of LANs a NAT Gateway is connected to",
+		}, []string{"account", "datacenter", "nat_id", "nat_name"}),
+		natLanInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nat_gateway_lan_info",
+			Help: "Shows a LAN a NAT Gateway is connected to with its gateway IPs, the value is always 1",
+		}, []string{"account", "datacenter", "nat_id", "nat_name", "lan_id", "gateway_ips"}),
+		natStateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nat_gateway_state",
+			Help: "Shows the current provisioning state of a NAT Gateway, the active state has the value 1",
+		}, []string{"account", "datacenter", "nat_id", "nat_name", "state"}),
+		serverInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_server_info",
+			Help: "Shows the name, CPU family, type and availability zone of a server in an IONOS datacenter, the value is always 1",
+		}, []string{"account", "datacenter", "server_id", "server", "cpu_family", "type", "availability_zone"}),
+		serverCoresMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_server_cores",
+			Help: "Shows the number of cores of a server in an IONOS datacenter",
+		}, []string{"account", "datacenter", "server_id"}),
+		serverRamMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_server_ram_bytes",
+			Help: "Shows the RAM of a server in an IONOS datacenter in bytes",
+		}, []string{"account", "datacenter", "server_id"}),
+		serverVmStateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_server_vm_state",
+			Help: "Shows the current VM state of a server in an IONOS datacenter, the active state has the value 1",
+		}, []string{"account", "datacenter", "server_id", "state"}),
 	}
 }
 
@@ -132,7 +268,33 @@ func (collector *ionosCollector) Describe(ch chan<- *prometheus.Desc) {
 	collector.dcALBRulesMetric.Describe(ch)
 	collector.dcNLBRulesMetric.Describe(ch)
 	collector.dcTotalIpsMetric.Describe(ch)
+	collector.dcInfoMetric.Describe(ch)
 	collector.apiFailuresMetric.Describe(ch)
+	collector.nlbRulesMetric.Describe(ch)
+	collector.nlbRuleTargetsMetric.Describe(ch)
+	collector.albRulesMetric.Describe(ch)
+	collector.albRuleTargetsMetric.Describe(ch)
+	collector.targetGroupTargetsMetric.Describe(ch)
+	collector.targetGroupHealthCheckTargetsMetric.Describe(ch)
+	collector.targetGroupCheckIntervalMetric.Describe(ch)
+	collector.targetGroupCheckTimeoutMetric.Describe(ch)
+	collector.targetGroupRetriesMetric.Describe(ch)
+	collector.targetGroupHttpHealthCheckMetric.Describe(ch)
+	collector.targetGroupReferencesMetric.Describe(ch)
+	collector.albRuleTargetGroupTargetsMetric.Describe(ch)
+	collector.ipBlockSizeMetric.Describe(ch)
+	collector.ipBlockUsedIpsMetric.Describe(ch)
+	collector.ipBlockUnusedIpsMetric.Describe(ch)
+	collector.totalUnusedIpsMetric.Describe(ch)
+	collector.natPublicIpsMetric.Describe(ch)
+	collector.natSNATRulesMetric.Describe(ch)
+	collector.natLansMetric.Describe(ch)
+	collector.natLanInfoMetric.Describe(ch)
+	collector.natStateMetric.Describe(ch)
+	collector.serverInfoMetric.Describe(ch)
+	collector.serverCoresMetric.Describe(ch)
+	collector.serverRamMetric.Describe(ch)
+	collector.serverVmStateMetric.Describe(ch)
 }
 
 // Collect implements required collect function for all promehteus collectors
@@ -140,35 +302,137 @@ func (collector *ionosCollector) Collect(ch chan<- prometheus.Metric) {
 
 	//Implement logic here to determine proper metric value to return to prometheus
 	//for each descriptor or call other functions that do so.
-	account := os.Getenv("IONOS_ACCOUNT")
 	collector.mutex.RLock()
 	defer collector.mutex.RUnlock()
 
-	// Reset metrics in case a datacenter was removed
+	// Reset metrics in case a datacenter or an account was removed
 	collector.coresMetric.Reset()
 	collector.ramMetric.Reset()
 	collector.serverMetric.Reset()
 	collector.albsMetric.Reset()
 	collector.natsMetric.Reset()
 	collector.nlbsMetric.Reset()
+	collector.nlbRulesMetric.Reset()
+	collector.nlbRuleTargetsMetric.Reset()
+	collector.albRulesMetric.Reset()
+	collector.albRuleTargetsMetric.Reset()
+	collector.targetGroupTargetsMetric.Reset()
+	collector.targetGroupHealthCheckTargetsMetric.Reset()
+	collector.targetGroupCheckIntervalMetric.Reset()
+	collector.targetGroupCheckTimeoutMetric.Reset()
+	collector.targetGroupRetriesMetric.Reset()
+	collector.targetGroupHttpHealthCheckMetric.Reset()
+	collector.targetGroupReferencesMetric.Reset()
+	collector.albRuleTargetGroupTargetsMetric.Reset()
+	collector.ipBlockSizeMetric.Reset()
+	collector.ipBlockUsedIpsMetric.Reset()
+	collector.ipBlockUnusedIpsMetric.Reset()
+	collector.natPublicIpsMetric.Reset()
+	collector.natSNATRulesMetric.Reset()
+	collector.natLansMetric.Reset()
+	collector.natLanInfoMetric.Reset()
+	collector.natStateMetric.Reset()
+	collector.serverInfoMetric.Reset()
+	collector.serverCoresMetric.Reset()
+	collector.serverRamMetric.Reset()
+	collector.serverVmStateMetric.Reset()
+	collector.dcCoresMetric.Reset()
+	collector.dcRamMetric.Reset()
+	collector.dcServerMetric.Reset()
+	collector.dcDCMetric.Reset()
+	collector.dcTotalIpsMetric.Reset()
+	collector.dcInfoMetric.Reset()
+	collector.totalUnusedIpsMetric.Reset()
 	// fmt.Println("Here are the metrics in ionosCollector", IonosDatacenters)
-	for dcName, dcResources := range IonosDatacenters {
-		//Write latest value for each metric in the prometheus metric channel.
-		collector.coresMetric.WithLabelValues(dcName).Set(float64(dcResources.Cores))
-		collector.ramMetric.WithLabelValues(dcName).Set(float64(dcResources.Ram / 1024)) // MB -> GB
-		collector.serverMetric.WithLabelValues(dcName).Set(float64(dcResources.Servers))
-		collector.nlbsMetric.WithLabelValues(dcName, dcResources.NLBName, dcResources.NLBRuleName).Set(float64(dcResources.NLBs))
-		collector.albsMetric.WithLabelValues(dcName, dcResources.ALBName, dcResources.ALBRuleName).Set(float64(dcResources.ALBs))
-		collector.natsMetric.WithLabelValues(dcName).Set(float64(dcResources.NATs))
-		collector.dcTotalIpsMetric.Set(float64(dcResources.TotalIPs))
-		collector.apiFailuresMetric.Add(float64(dcResources.TotalAPICallFailures))
+	for account, datacenters := range IonosDatacenters {
+		for dcName, dcResources := range datacenters {
+			//Write latest value for each metric in the prometheus metric channel.
+			collector.dcInfoMetric.WithLabelValues(account, dcName, dcResources.DCId, dcResources.Location).Set(1)
+			collector.coresMetric.WithLabelValues(account, dcName).Set(float64(dcResources.Cores))
+			collector.ramMetric.WithLabelValues(account, dcName).Set(float64(dcResources.Ram / 1024)) // MB -> GB
+			collector.serverMetric.WithLabelValues(account, dcName).Set(float64(dcResources.Servers))
+			collector.nlbsMetric.WithLabelValues(account, dcName).Set(float64(dcResources.NLBs))
+			collector.albsMetric.WithLabelValues(account, dcName).Set(float64(dcResources.ALBs))
+			collector.natsMetric.WithLabelValues(account, dcName).Set(float64(dcResources.NATs))
+			collector.apiFailuresMetric.WithLabelValues(account).Add(float64(dcResources.TotalAPICallFailures))
 
+			for _, nlb := range dcResources.NLBDetails {
+				collector.nlbRulesMetric.WithLabelValues(account, dcName, nlb.Id, nlb.Name, nlb.ListenerIps, nlb.State).Set(float64(len(nlb.Rules)))
+				for _, rule := range nlb.Rules {
+					collector.nlbRuleTargetsMetric.WithLabelValues(account, dcName, nlb.Id, nlb.Name, rule.Id, rule.Name, rule.Protocol, strconv.Itoa(int(rule.ListenerPort))).Set(float64(rule.Targets))
+				}
+			}
+			for _, alb := range dcResources.ALBDetails {
+				collector.albRulesMetric.WithLabelValues(account, dcName, alb.Id, alb.Name, alb.ListenerIps, alb.State).Set(float64(len(alb.Rules)))
+				for _, rule := range alb.Rules {
+					collector.albRuleTargetsMetric.WithLabelValues(account, dcName, alb.Id, alb.Name, rule.Id, rule.Name, rule.Protocol, strconv.Itoa(int(rule.ListenerPort))).Set(float64(rule.Targets))
+					for _, targetGroupId := range rule.TargetGroups {
+						// Unknown target groups are reported with an empty name and 0 targets
+						targetGroup := IonosTargetGroups[account][targetGroupId]
+						collector.albRuleTargetGroupTargetsMetric.WithLabelValues(account, dcName, alb.Id, alb.Name, rule.Id, rule.Name, targetGroupId, targetGroup.Name).Set(float64(targetGroup.Targets))
+					}
+				}
+			}
+
+			for _, nat := range dcResources.NATDetails {
+				collector.natPublicIpsMetric.WithLabelValues(account, dcName, nat.Id, nat.Name, strings.Join(nat.PublicIps, ",")).Set(float64(len(nat.PublicIps)))
+				collector.natSNATRulesMetric.WithLabelValues(account, dcName, nat.Id, nat.Name).Set(float64(nat.SNATRules))
+				collector.natLansMetric.WithLabelValues(account, dcName, nat.Id, nat.Name).Set(float64(len(nat.Lans)))
+				collector.natStateMetric.WithLabelValues(account, dcName, nat.Id, nat.Name, nat.State).Set(1)
+				for _, lan := range nat.Lans {
+					collector.natLanInfoMetric.WithLabelValues(account, dcName, nat.Id, nat.Name, strconv.Itoa(int(lan.Id)), lan.GatewayIps).Set(1)
+				}
+			}
+
+			for _, server := range dcResources.ServerDetails {
+				collector.serverInfoMetric.WithLabelValues(account, dcName, server.Id, server.Name, server.CpuFamily, server.Type, server.AvailabilityZone).Set(1)
+				collector.serverCoresMetric.WithLabelValues(account, dcName, server.Id).Set(float64(server.Cores))
+				collector.serverRamMetric.WithLabelValues(account, dcName, server.Id).Set(float64(server.Ram) * 1024 * 1024) // MB -> Bytes
+				collector.serverVmStateMetric.WithLabelValues(account, dcName, server.Id, server.VmState).Set(1)
+			}
+		}
+	}
+
+	for account, targetGroups := range IonosTargetGroups {
+		for targetGroupId, targetGroup := range targetGroups {
+			collector.targetGroupTargetsMetric.WithLabelValues(account, targetGroupId, targetGroup.Name, targetGroup.Algorithm, targetGroup.Protocol).Set(float64(targetGroup.Targets))
+			collector.targetGroupHealthCheckTargetsMetric.WithLabelValues(account, targetGroupId, targetGroup.Name).Set(float64(targetGroup.HealthCheckEnabledTargets))
+			collector.targetGroupCheckIntervalMetric.WithLabelValues(account, targetGroupId, targetGroup.Name).Set(float64(targetGroup.CheckInterval))
+			collector.targetGroupCheckTimeoutMetric.WithLabelValues(account, targetGroupId, targetGroup.Name).Set(float64(targetGroup.CheckTimeout))
+			collector.targetGroupRetriesMetric.WithLabelValues(account, targetGroupId, targetGroup.Name).Set(float64(targetGroup.Retries))
+			collector.targetGroupHttpHealthCheckMetric.WithLabelValues(account, targetGroupId, targetGroup.Name, targetGroup.HttpHealthCheckPath, targetGroup.HttpHealthCheckMethod, targetGroup.HttpHealthCheckMatchType, targetGroup.HttpHealthCheckResponse).Set(1)
+			if !targetGroup.ReferencesUnknown {
+				collector.targetGroupReferencesMetric.WithLabelValues(account, targetGroupId, targetGroup.Name).Set(float64(targetGroup.References))
+			}
+		}
 	}
 
-	collector.dcCoresMetric.WithLabelValues(account).Set(float64(CoresTotal))
-	collector.dcRamMetric.WithLabelValues(account).Set(float64(RamTotal / 1024)) // MB -> GB
-	collector.dcServerMetric.WithLabelValues(account).Set(float64(ServerTotal))
-	collector.dcDCMetric.WithLabelValues(account).Set(float64(DataCenters))
+	for account, ipBlocks := range IonosIPBlocks {
+		var totalIps, totalUnusedIps int32
+		for ipBlockId, ipBlock := range ipBlocks {
+			unusedIps := ipBlock.Size
+			collector.ipBlockSizeMetric.WithLabelValues(account, ipBlockId, ipBlock.Name, ipBlock.Location).Set(float64(ipBlock.Size))
+			for consumer, usedIps := range ipBlock.UsedIps {
+				collector.ipBlockUsedIpsMetric.WithLabelValues(account, ipBlockId, ipBlock.Name, ipBlock.Location, consumer).Set(float64(usedIps))
+				unusedIps -= usedIps
+			}
+			if unusedIps < 0 {
+				unusedIps = 0
+			}
+			collector.ipBlockUnusedIpsMetric.WithLabelValues(account, ipBlockId, ipBlock.Name, ipBlock.Location).Set(float64(unusedIps))
+			totalIps += ipBlock.Size
+			totalUnusedIps += unusedIps
+		}
+		collector.dcTotalIpsMetric.WithLabelValues(account).Set(float64(totalIps))
+		collector.totalUnusedIpsMetric.WithLabelValues(account).Set(float64(totalUnusedIps))
+	}
+
+	for account, totals := range IonosTotals {
+		collector.dcCoresMetric.WithLabelValues(account).Set(float64(totals.Cores))
+		collector.dcRamMetric.WithLabelValues(account).Set(float64(totals.Ram / 1024)) // MB -> GB
+		collector.dcServerMetric.WithLabelValues(account).Set(float64(totals.Servers))
+		collector.dcDCMetric.WithLabelValues(account).Set(float64(totals.DataCenters))
+	}
 
 	collector.coresMetric.Collect(ch)
 	collector.ramMetric.Collect(ch)
@@ -186,5 +450,31 @@ func (collector *ionosCollector) Collect(ch chan<- prometheus.Metric) {
 	collector.dcNLBRulesMetric.Collect(ch)
 	collector.dcALBRulesMetric.Collect(ch)
 	collector.dcTotalIpsMetric.Collect(ch)
+	collector.dcInfoMetric.Collect(ch)
 	collector.apiFailuresMetric.Collect(ch)
+	collector.nlbRulesMetric.Collect(ch)
+	collector.nlbRuleTargetsMetric.Collect(ch)
+	collector.albRulesMetric.Collect(ch)
+	collector.albRuleTargetsMetric.Collect(ch)
+	collector.targetGroupTargetsMetric.Collect(ch)
+	collector.targetGroupHealthCheckTargetsMetric.Collect(ch)
+	collector.targetGroupCheckIntervalMetric.Collect(ch)
+	collector.targetGroupCheckTimeoutMetric.Collect(ch)
+	collector.targetGroupRetriesMetric.Collect(ch)
+	collector.targetGroupHttpHealthCheckMetric.Collect(ch)
+	collector.targetGroupReferencesMetric.Collect(ch)
+	collector.albRuleTargetGroupTargetsMetric.Collect(ch)
+	collector.ipBlockSizeMetric.Collect(ch)
+	collector.ipBlockUsedIpsMetric.Collect(ch)
+	collector.ipBlockUnusedIpsMetric.Collect(ch)
+	collector.totalUnusedIpsMetric.Collect(ch)
+	collector.natPublicIpsMetric.Collect(ch)
+	collector.natSNATRulesMetric.Collect(ch)
+	collector.natLansMetric.Collect(ch)
+	collector.natLanInfoMetric.Collect(ch)
+	collector.natStateMetric.Collect(ch)
+	collector.serverInfoMetric.Collect(ch)
+	collector.serverCoresMetric.Collect(ch)
+	collector.serverRamMetric.Collect(ch)
+	collector.serverVmStateMetric.Collect(ch)
 }
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type connectivityCollector struct {
+	mutex                            *sync.RWMutex
+	crossConnectStateMetric          *prometheus.GaugeVec
+	crossConnectPeersMetric          *prometheus.GaugeVec
+	crossConnectPeerInfoMetric       *prometheus.GaugeVec
+	crossConnectConnectableDCsMetric *prometheus.GaugeVec
+	vpnGatewayStatusMetric           *prometheus.GaugeVec
+	vpnGatewayConnectionsMetric      *prometheus.GaugeVec
+	vpnGatewayTunnelsMetric          *prometheus.GaugeVec
+	vpnTunnelStatusMetric            *prometheus.GaugeVec
+}
+
+func NewConnectivityCollector(m *sync.RWMutex) *connectivityCollector {
+	return &connectivityCollector{
+		mutex: m,
+		crossConnectStateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_crossconnect_state",
+			Help: "Shows the current provisioning state of a private cross connect, the active state has the value 1",
+		}, []string{"account", "crossconnect_id", "crossconnect", "state"}),
+		crossConnectPeersMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_crossconnect_peers_amount",
+			Help: "Shows the number of LANs connected through a private cross connect",
+		}, []string{"account", "crossconnect_id", "crossconnect"}),
+		crossConnectPeerInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_crossconnect_peer_info",
+			Help: "Shows a LAN connected through a private cross connect, the value is always 1",
+		}, []string{"account", "crossconnect_id", "crossconnect", "datacenter", "datacenter_id", "location", "lan_id", "lan_name"}),
+		crossConnectConnectableDCsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_crossconnect_connectable_datacenters_amount",
+			Help: "Shows the number of datacenters which could be connected to a private cross connect",
+		}, []string{"account", "crossconnect_id", "crossconnect"}),
+		vpnGatewayStatusMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_vpn_gateway_status",
+			Help: "Shows the current status of an IPSec or WireGuard VPN gateway, the active status has the value 1",
+		}, []string{"account", "gateway_id", "gateway", "type", "location", "gateway_ip", "status"}),
+		vpnGatewayConnectionsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_vpn_gateway_connections_amount",
+			Help: "Shows the number of LANs a VPN gateway is connected to",
+		}, []string{"account", "gateway_id", "gateway", "type", "location"}),
+		vpnGatewayTunnelsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_vpn_gateway_tunnels_amount",
+			Help: "Shows the number of IPSec tunnels or WireGuard peers of a VPN gateway",
+		}, []string{"account", "gateway_id", "gateway", "type", "location"}),
+		vpnTunnelStatusMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_vpn_tunnel_status",
+			Help: "Shows the current status of an IPSec tunnel or WireGuard peer, the active status has the value 1",
+		}, []string{"account", "gateway_id", "gateway", "type", "location", "tunnel_id", "tunnel", "remote", "status"}),
+	}
+}
+
+func (collector *connectivityCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.crossConnectStateMetric.Describe(ch)
+	collector.crossConnectPeersMetric.Describe(ch)
+	collector.crossConnectPeerInfoMetric.Describe(ch)
+	collector.crossConnectConnectableDCsMetric.Describe(ch)
+	collector.vpnGatewayStatusMetric.Describe(ch)
+	collector.vpnGatewayConnectionsMetric.Describe(ch)
+	collector.vpnGatewayTunnelsMetric.Describe(ch)
+	collector.vpnTunnelStatusMetric.Describe(ch)
+}
+
+func (collector *connectivityCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+
+	// Reset metrics in case a cross connect, VPN gateway or account was removed
+	collector.crossConnectStateMetric.Reset()
+	collector.crossConnectPeersMetric.Reset()
+	collector.crossConnectPeerInfoMetric.Reset()
+	collector.crossConnectConnectableDCsMetric.Reset()
+	collector.vpnGatewayStatusMetric.Reset()
+	collector.vpnGatewayConnectionsMetric.Reset()
+	collector.vpnGatewayTunnelsMetric.Reset()
+	collector.vpnTunnelStatusMetric.Reset()
+
+	for account, crossConnects := range IonosCrossConnects {
+		for crossConnectId, crossConnect := range crossConnects {
+			collector.crossConnectStateMetric.WithLabelValues(account, crossConnectId, crossConnect.Name, crossConnect.State).Set(1)
+			collector.crossConnectPeersMetric.WithLabelValues(account, crossConnectId, crossConnect.Name).Set(float64(len(crossConnect.Peers)))
+			collector.crossConnectConnectableDCsMetric.WithLabelValues(account, crossConnectId, crossConnect.Name).Set(float64(crossConnect.ConnectableDatacenters))
+			for _, peer := range crossConnect.Peers {
+				collector.crossConnectPeerInfoMetric.WithLabelValues(account, crossConnectId, crossConnect.Name, peer.DatacenterName, peer.DatacenterId, peer.Location, peer.LanId, peer.LanName).Set(1)
+			}
+		}
+	}
+
+	for account, gateways := range IonosVPNGateways {
+		for gatewayId, gateway := range gateways {
+			collector.vpnGatewayStatusMetric.WithLabelValues(account, gatewayId, gateway.Name, gateway.Type, gateway.Location, gateway.GatewayIp, gateway.Status).Set(1)
+			collector.vpnGatewayConnectionsMetric.WithLabelValues(account, gatewayId, gateway.Name, gateway.Type, gateway.Location).Set(float64(gateway.Connections))
+			collector.vpnGatewayTunnelsMetric.WithLabelValues(account, gatewayId, gateway.Name, gateway.Type, gateway.Location).Set(float64(len(gateway.Tunnels)))
+			for _, tunnel := range gateway.Tunnels {
+				collector.vpnTunnelStatusMetric.WithLabelValues(account, gatewayId, gateway.Name, gateway.Type, gateway.Location, tunnel.Id, tunnel.Name, tunnel.Remote, tunnel.Status).Set(1)
+			}
+		}
+	}
+
+	collector.crossConnectStateMetric.Collect(ch)
+	collector.crossConnectPeersMetric.Collect(ch)
+	collector.crossConnectPeerInfoMetric.Collect(ch)
+	collector.crossConnectConnectableDCsMetric.Collect(ch)
+	collector.vpnGatewayStatusMetric.Collect(ch)
+	collector.vpnGatewayConnectionsMetric.Collect(ch)
+	collector.vpnGatewayTunnelsMetric.Collect(ch)
+	collector.vpnTunnelStatusMetric.Collect(ch)
+}
@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+// Shared by all VPN API calls, the timeout bounds them even if the collector has no timeout
+var vpnClient = &http.Client{Timeout: 30 * time.Second}
+
+var (
+	IonosCrossConnects = make(map[string]map[string]IonosCrossConnectResources) //Key is the name of the account, then the UUID of the cross connect
+	IonosVPNGateways   = make(map[string]map[string]IonosVPNGatewayResources)   //Key is the name of the account, then the UUID of the VPN gateway
+)
+
+type IonosCrossConnectResources struct {
+	Name                   string                  // Name of the cross connect
+	State                  string                  // Provisioning state of the cross connect, e.g. AVAILABLE or BUSY
+	ConnectableDatacenters int32                   // Number of datacenters which could be connected to the cross connect
+	Peers                  []IonosCrossConnectPeer // LANs connected through the cross connect
+}
+
+type IonosCrossConnectPeer struct {
+	LanId          string // ID of the connected LAN
+	LanName        string // Name of the connected LAN
+	DatacenterId   string // UUID of the datacenter of the LAN
+	DatacenterName string // Name of the datacenter of the LAN
+	Location       string // Location of the datacenter of the LAN
+}
+
+type IonosVPNGatewayResources struct {
+	Name        string                    // Name of the VPN gateway
+	Type        string                    // ipsec or wireguard
+	Location    string                    // Location of the VPN API the gateway was found in, e.g. de-fra
+	GatewayIp   string                    // Public IP of the gateway
+	Status      string                    // Status of the gateway, e.g. AVAILABLE or FAILED
+	Connections int32                     // Number of LANs the gateway is connected to
+	Tunnels     []IonosVPNTunnelResources // IPSec tunnels or WireGuard peers of the gateway
+}
+
+type IonosVPNTunnelResources struct {
+	Id     string // UUID of the tunnel or peer
+	Name   string // Name of the tunnel or peer
+	Remote string // Remote host of the tunnel or endpoint host of the peer
+	Status string // Status of the tunnel or peer, e.g. AVAILABLE or FAILED
+}
+
+// Subset of the IONOS VPN Gateway API list responses, see https://api.ionos.com/docs/vpn/v1/
+type vpnGatewayList struct {
+	Items []struct {
+		Id       string `json:"id"`
+		Metadata struct {
+			Status string `json:"status"`
+		} `json:"metadata"`
+		Properties struct {
+			Name        string        `json:"name"`
+			GatewayIP   string        `json:"gatewayIP"`
+			Connections []interface{} `json:"connections"`
+		} `json:"properties"`
+	} `json:"items"`
+}
+
+type vpnTunnelList struct {
+	Items []struct {
+		Id       string `json:"id"`
+		Metadata struct {
+			Status string `json:"status"`
+		} `json:"metadata"`
+		Properties struct {
+			Name       string `json:"name"`
+			RemoteHost string `json:"remoteHost"`
+			Endpoint   struct {
+				Host string `json:"host"`
+			} `json:"endpoint"`
+		} `json:"properties"`
+	} `json:"items"`
+}
+
+func ConnectivityCollectResources(m *sync.RWMutex, account AccountConfig, config ConnectivityCollectorConfig) {
+	cfgENV := account.IonosConfiguration()
+	apiClient := ionoscloud.NewAPIClient(cfgENV)
+
+	config.Run("connectivity", account, func(ctx context.Context) {
+		crossConnects, err := fetchCrossConnects(ctx, apiClient)
+		if err != nil {
+			fmt.Printf("Error retrieving cross connects: %v\n", err)
+		}
+
+		// The maps are replaced and never modified, so the previous one can be read after unlocking
+		m.RLock()
+		previousVPNGateways := IonosVPNGateways[account.Name]
+		m.RUnlock()
+		newIonosVPNGateways := make(map[string]IonosVPNGatewayResources)
+		for _, location := range config.VPNLocations {
+			for _, gatewayType := range []string{"ipsec", "wireguard"} {
+				if err := fetchVPNGateways(ctx, cfgENV, location, gatewayType, previousVPNGateways, newIonosVPNGateways); err != nil {
+					fmt.Printf("Error retrieving %s gateways in %s, keeping the previous data: %v\n", gatewayType, location, err)
+					for id, gateway := range previousVPNGateways {
+						if gateway.Location == location && gateway.Type == gatewayType {
+							newIonosVPNGateways[id] = gateway
+						}
+					}
+				}
+			}
+		}
+
+		m.Lock()
+		if crossConnects != nil {
+			IonosCrossConnects[account.Name] = processCrossConnects(crossConnects)
+		}
+		IonosVPNGateways[account.Name] = newIonosVPNGateways
+		m.Unlock()
+	})
+}
+
+/*
+Retrieves the list of all private cross connects of the account using the ionoscloud API Client
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+
+Returns:
+  - *ionoscloud.PrivateCrossConnects: A pointer to ionoscloud.PrivateCrossConnects or an error if it fails
+*/
+func fetchCrossConnects(ctx context.Context, apiClient *ionoscloud.APIClient) (*ionoscloud.PrivateCrossConnects, error) {
+	crossConnects, resp, err := apiClient.PrivateCrossConnectsApi.PccsGet(ctx).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling PrivateCrossConnects API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if crossConnects.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &crossConnects, nil
+}
+
+/*
+Extracts state, connectable datacenters and connected LANs of every cross connect
+
+Parameters:
+  - crossConnects: A pointer to ionoscloud.PrivateCrossConnects containing the cross connects to process
+
+Returns:
+  - map[string]IonosCrossConnectResources: cross connect details keyed by the cross connect UUID
+*/
+func processCrossConnects(crossConnects *ionoscloud.PrivateCrossConnects) map[string]IonosCrossConnectResources {
+	crossConnectDetails := make(map[string]IonosCrossConnectResources)
+	for _, crossConnect := range *crossConnects.Items {
+		if crossConnect.Id == nil || crossConnect.Properties == nil {
+			fmt.Println("CrossConnect Id or CrossConnect Properties are nil")
+			continue
+		}
+		crossConnectDetail := IonosCrossConnectResources{
+			Name:                   ionoscloud.ToValueDefault(crossConnect.Properties.Name),
+			ConnectableDatacenters: int32(len(ionoscloud.ToValueDefault(crossConnect.Properties.ConnectableDatacenters))),
+		}
+		if crossConnect.Metadata != nil {
+			crossConnectDetail.State = ionoscloud.ToValueDefault(crossConnect.Metadata.State)
+		}
+		for _, peer := range ionoscloud.ToValueDefault(crossConnect.Properties.Peers) {
+			crossConnectDetail.Peers = append(crossConnectDetail.Peers, IonosCrossConnectPeer{
+				LanId:          ionoscloud.ToValueDefault(peer.Id),
+				LanName:        ionoscloud.ToValueDefault(peer.Name),
+				DatacenterId:   ionoscloud.ToValueDefault(peer.DatacenterId),
+				DatacenterName: ionoscloud.ToValueDefault(peer.DatacenterName),
+				Location:       ionoscloud.ToValueDefault(peer.Location),
+			})
+		}
+		crossConnectDetails[*crossConnect.Id] = crossConnectDetail
+	}
+	return crossConnectDetails
+}
+
+/*
+Retrieves the IPSec or WireGuard gateways of one location including their tunnels or peers.
+The VPN Gateway API is not part of the ionoscloud SDK, so it is queried directly.
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - cfg: the ionoscloud configuration, used for the credentials
+  - location: location of the VPN API, e.g. de-fra
+  - gatewayType: ipsec or wireguard
+  - previous: the gateways of the previous run, a gateway whose tunnels can not be retrieved is kept as it was
+  - gateways: map the found gateways are added to, keyed by the gateway UUID
+
+Returns:
+  - error: An error if the gateways could not be retrieved
+*/
+func fetchVPNGateways(ctx context.Context, cfg *ionoscloud.Configuration, location string, gatewayType string, previous map[string]IonosVPNGatewayResources, gateways map[string]IonosVPNGatewayResources) error {
+	baseUrl := fmt.Sprintf("https://vpn.%s.ionos.com/%sgateways", location, gatewayType)
+	tunnelPath := "tunnels"
+	if gatewayType == "wireguard" {
+		tunnelPath = "peers"
+	}
+
+	var gatewayList vpnGatewayList
+	if err := fetchVPNResource(ctx, cfg, baseUrl, &gatewayList); err != nil {
+		return err
+	}
+	for _, gateway := range gatewayList.Items {
+		gatewayDetail := IonosVPNGatewayResources{
+			Name:        gateway.Properties.Name,
+			Type:        gatewayType,
+			Location:    location,
+			GatewayIp:   gateway.Properties.GatewayIP,
+			Status:      gateway.Metadata.Status,
+			Connections: int32(len(gateway.Properties.Connections)),
+		}
+		var tunnelList vpnTunnelList
+		if err := fetchVPNResource(ctx, cfg, fmt.Sprintf("%s/%s/%s", baseUrl, gateway.Id, tunnelPath), &tunnelList); err != nil {
+			// Without its tunnels the gateway would look like a gateway without tunnels, so the previous data is kept
+			fmt.Printf("Error retrieving %s of VPN gateway %s, keeping the previous data: %v\n", tunnelPath, gateway.Properties.Name, err)
+			if previousGateway, ok := previous[gateway.Id]; ok {
+				gateways[gateway.Id] = previousGateway
+			}
+			continue
+		}
+		for _, tunnel := range tunnelList.Items {
+			remote := tunnel.Properties.RemoteHost
+			if remote == "" {
+				remote = tunnel.Properties.Endpoint.Host
+			}
+			gatewayDetail.Tunnels = append(gatewayDetail.Tunnels, IonosVPNTunnelResources{
+				Id:     tunnel.Id,
+				Name:   tunnel.Properties.Name,
+				Remote: remote,
+				Status: tunnel.Metadata.Status,
+			})
+		}
+		gateways[gateway.Id] = gatewayDetail
+	}
+	return nil
+}
+
+func fetchVPNResource(ctx context.Context, cfg *ionoscloud.Configuration, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	} else {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	resp, err := vpnClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %s from %s", resp.Status, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		fmt.Printf("Failed to decode json response: %v\n", err)
+		return err
+	}
+	return nil
+}
@@ -6,65 +6,102 @@ import (
 	"os"
 	"reflect"
 	"sync"
-	"time"
 
 	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Pairs of ResourceLimits fields, the contract limit and the usage aggregated across all datacenters of the contract
+var contractUtilizationFields = []struct {
+	Resource    string
+	Limit       string
+	Provisioned string
+}{
+	{"cores", "CoresPerContract", "CoresProvisioned"},
+	{"ram", "RamPerContract", "RamProvisioned"},
+	{"hdd", "HddLimitPerContract", "HddVolumeProvisioned"},
+	{"ssd", "SsdLimitPerContract", "SsdVolumeProvisioned"},
+	{"ips", "ReservedIpsOnContract", "ReservedIpsInUse"},
+	{"k8s_clusters", "K8sClusterLimitTotal", "K8sClustersProvisioned"},
+	{"nat_gateways", "NatGatewayLimitTotal", "NatGatewayProvisioned"},
+	{"nlbs", "NlbLimitTotal", "NlbProvisioned"},
+}
+
+var (
+	contractUtilizationDesc = prometheus.NewDesc(
+		"ionos_contract_utilization_ratio",
+		"Ratio of the provisioned amount of a resource to its contract limit, only exported for resources with a positive limit",
+		[]string{"account", "contract", "resource"},
+		nil,
+	)
+	contractHeadroomDesc = prometheus.NewDesc(
+		"ionos_contract_headroom",
+		"Amount of a resource which can still be provisioned until the contract limit is reached, in the unit of the limit (MB for ram, hdd and ssd)",
+		[]string{"account", "contract", "resource"},
+		nil,
+	)
+	contractFetchErrorDesc = prometheus.NewDesc(
+		"ionos_contract_fetch_error",
+		"Error during fetch/generation of contract resource limits metrics",
+		[]string{"account"},
+		nil,
+	)
+)
+
 type ContractLimitsCollector struct {
 	mutex        sync.RWMutex
-	contractData *ionoscloud.Contracts
+	contractData map[string]*ionoscloud.Contracts //Key is the name of the account
 	promDescs    map[string]*prometheus.Desc
 }
 
 func NewContractLimitsCollector() *ContractLimitsCollector {
 	return &ContractLimitsCollector{
-		promDescs: make(map[string]*prometheus.Desc),
+		contractData: make(map[string]*ionoscloud.Contracts),
+		promDescs:    make(map[string]*prometheus.Desc),
 	}
 }
 
 // Uncheked Collector: Descriptions will be generated dynamically
 func (c *ContractLimitsCollector) Describe(ch chan<- *prometheus.Desc) {}
 
-func (c *ContractLimitsCollector) StartScrape(cycletime int32) {
-	cfgENV := ionoscloud.NewConfigurationFromEnv()
+func (c *ContractLimitsCollector) StartScrape(account AccountConfig, config CollectorConfig) {
+	cfgENV := account.IonosConfiguration()
 	apiClient := ionoscloud.NewAPIClient(cfgENV)
 
-	for {
-		contracts, resp, err := apiClient.ContractResourcesApi.ContractsGet(context.Background()).Execute()
+	config.Run("contract limits", account, func(ctx context.Context) {
+		contracts, resp, err := apiClient.ContractResourcesApi.ContractsGet(ctx).Execute()
 		c.mutex.Lock()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error when calling `ContractResourcesApi.ContractsGet``: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Full HTTP response: %+v\n", resp)
-			c.contractData = nil
+			c.contractData[account.Name] = nil
 		} else {
-			c.contractData = &contracts
+			c.contractData[account.Name] = &contracts
 		}
 		c.mutex.Unlock()
-		time.Sleep(time.Duration(cycletime) * time.Second)
-	}
+	})
 }
 
 func (c *ContractLimitsCollector) Collect(ch chan<- prometheus.Metric) {
-	fetchErrorMetric := 1.0
 	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for account, contractData := range c.contractData {
+		c.collectAccount(ch, account, contractData)
+	}
+}
+
+func (c *ContractLimitsCollector) collectAccount(ch chan<- prometheus.Metric, account string, contractData *ionoscloud.Contracts) {
+	fetchErrorMetric := 1.0
 	//Ensure clean finish in case of errors
 	defer func() {
-		c.mutex.RUnlock()
 		if err := recover(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error while converting IONOS response to prometheus metrics: %v\n", err)
 		}
-		ch <- prometheus.MustNewConstMetric(prometheus.NewDesc(
-			"ionos_contract_fetch_error",
-			"Error during fetch/generation of contract resource limits metrics",
-			nil,
-			nil,
-		), prometheus.GaugeValue, fetchErrorMetric)
+		ch <- prometheus.MustNewConstMetric(contractFetchErrorDesc, prometheus.GaugeValue, fetchErrorMetric, account)
 	}()
 
-	if c.contractData != nil && c.contractData.Items != nil {
-		for _, contract := range *c.contractData.Items {
+	if contractData != nil && contractData.Items != nil {
+		for _, contract := range *contractData.Items {
 			if contract.Properties == nil || contract.Properties.ResourceLimits == nil || contract.Properties.ContractNumber == nil {
 				fmt.Fprintf(os.Stderr, "Contract is missing neccessary field, skip: %+v\n", contract)
 				continue
@@ -75,11 +112,20 @@ func (c *ContractLimitsCollector) Collect(ch chan<- prometheus.Metric) {
 			for i := 0; i < values.NumField(); i++ {
 				name := names.Field(i).Name
 				value := values.Field(i).Elem()
-				if !value.CanInt(){
+				if !value.CanInt() {
 					fmt.Fprintf(os.Stderr, "Expected int for metrics, but %q is %v, skip.\n", name, value.Kind())
 					continue
 				}
-				ch <- prometheus.MustNewConstMetric(c.getDesc(name), prometheus.GaugeValue, float64(value.Int()), contractId)
+				ch <- prometheus.MustNewConstMetric(c.getDesc(name), prometheus.GaugeValue, float64(value.Int()), account, contractId)
+			}
+			for _, field := range contractUtilizationFields {
+				limit := values.FieldByName(field.Limit).Elem()
+				provisioned := values.FieldByName(field.Provisioned).Elem()
+				if !limit.CanInt() || !provisioned.CanInt() || limit.Int() <= 0 {
+					continue
+				}
+				ch <- prometheus.MustNewConstMetric(contractUtilizationDesc, prometheus.GaugeValue, float64(provisioned.Int())/float64(limit.Int()), account, contractId, field.Resource)
+				ch <- prometheus.MustNewConstMetric(contractHeadroomDesc, prometheus.GaugeValue, float64(limit.Int()-provisioned.Int()), account, contractId, field.Resource)
 			}
 		}
 		fetchErrorMetric = 0
@@ -93,7 +139,7 @@ func (c *ContractLimitsCollector) getDesc(name string) *prometheus.Desc {
 		desc := prometheus.NewDesc(
 			"ionos_contract_"+ToSnake(name),
 			"Contract resource limits metrics via IONOS API. More details: https://api.ionos.com/docs/cloud/v6/#tag/Contract-resources/operation/contractsGet",
-			[]string{"contract"},
+			[]string{"account", "contract"},
 			nil,
 		)
 		c.promDescs[name] = desc
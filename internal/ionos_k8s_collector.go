@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type k8sCollector struct {
+	mutex                      *sync.RWMutex
+	clusterStateMetric         *prometheus.GaugeVec
+	nodePoolStateMetric        *prometheus.GaugeVec
+	nodePoolDesiredNodesMetric *prometheus.GaugeVec
+	nodePoolActualNodesMetric  *prometheus.GaugeVec
+	nodePoolMinNodesMetric     *prometheus.GaugeVec
+	nodePoolMaxNodesMetric     *prometheus.GaugeVec
+	nodePoolCoresMetric        *prometheus.GaugeVec
+	nodePoolRamMetric          *prometheus.GaugeVec
+	nodePoolStorageMetric      *prometheus.GaugeVec
+	dcK8sCoresMetric           *prometheus.GaugeVec
+	dcK8sRamMetric             *prometheus.GaugeVec
+	dcK8sNodesMetric           *prometheus.GaugeVec
+	totalClustersMetric        *prometheus.GaugeVec
+}
+
+func NewK8sCollector(m *sync.RWMutex) *k8sCollector {
+	nodePoolLabels := []string{"account", "cluster", "nodepool", "nodepool_id", "datacenter"}
+	return &k8sCollector{
+		mutex: m,
+		clusterStateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_cluster_state",
+			Help: "Shows the current state of an IONOS managed kubernetes cluster, the active state has the value 1",
+		}, []string{"account", "cluster", "cluster_id", "k8s_version", "state"}),
+		nodePoolStateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_state",
+			Help: "Shows the current state of a kubernetes node pool, the active state has the value 1",
+		}, append(nodePoolLabels, "k8s_version", "state")),
+		nodePoolDesiredNodesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_desired_nodes_amount",
+			Help: "Shows the desired number of nodes in a kubernetes node pool",
+		}, nodePoolLabels),
+		nodePoolActualNodesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_actual_nodes_amount",
+			Help: "Shows the number of nodes which currently exist in a kubernetes node pool",
+		}, nodePoolLabels),
+		nodePoolMinNodesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_autoscaling_min_nodes",
+			Help: "Shows the minimum number of nodes of a kubernetes node pool with autoscaling",
+		}, nodePoolLabels),
+		nodePoolMaxNodesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_autoscaling_max_nodes",
+			Help: "Shows the maximum number of nodes of a kubernetes node pool with autoscaling",
+		}, nodePoolLabels),
+		nodePoolCoresMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_cores_amount",
+			Help: "Shows the number of cores of all nodes in a kubernetes node pool",
+		}, nodePoolLabels),
+		nodePoolRamMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_ram_gb",
+			Help: "Shows the RAM of all nodes in a kubernetes node pool in GB",
+		}, nodePoolLabels),
+		nodePoolStorageMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_nodepool_storage_gb",
+			Help: "Shows the storage of all nodes in a kubernetes node pool in GB",
+		}, append(nodePoolLabels, "storage_type")),
+		dcK8sCoresMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_dc_cores_amount",
+			Help: "Shows the number of cores used by kubernetes nodes in an IONOS datacenter",
+		}, []string{"account", "datacenter"}),
+		dcK8sRamMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_dc_ram_gb",
+			Help: "Shows the RAM used by kubernetes nodes in an IONOS datacenter in GB",
+		}, []string{"account", "datacenter"}),
+		dcK8sNodesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_k8s_dc_nodes_amount",
+			Help: "Shows the number of kubernetes nodes in an IONOS datacenter",
+		}, []string{"account", "datacenter"}),
+		totalClustersMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_total_k8s_cluster_amount",
+			Help: "Shows the total number of kubernetes clusters in IONOS Account",
+		}, []string{"account"}),
+	}
+}
+
+// Kubernetes resources of an account in one datacenter
+type k8sDatacenterKey struct {
+	account    string
+	datacenter string
+}
+
+func (collector *k8sCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.clusterStateMetric.Describe(ch)
+	collector.nodePoolStateMetric.Describe(ch)
+	collector.nodePoolDesiredNodesMetric.Describe(ch)
+	collector.nodePoolActualNodesMetric.Describe(ch)
+	collector.nodePoolMinNodesMetric.Describe(ch)
+	collector.nodePoolMaxNodesMetric.Describe(ch)
+	collector.nodePoolCoresMetric.Describe(ch)
+	collector.nodePoolRamMetric.Describe(ch)
+	collector.nodePoolStorageMetric.Describe(ch)
+	collector.dcK8sCoresMetric.Describe(ch)
+	collector.dcK8sRamMetric.Describe(ch)
+	collector.dcK8sNodesMetric.Describe(ch)
+	collector.totalClustersMetric.Describe(ch)
+}
+
+func (collector *k8sCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+
+	// Reset metrics in case a cluster or node pool was removed
+	collector.clusterStateMetric.Reset()
+	collector.nodePoolStateMetric.Reset()
+	collector.nodePoolDesiredNodesMetric.Reset()
+	collector.nodePoolActualNodesMetric.Reset()
+	collector.nodePoolMinNodesMetric.Reset()
+	collector.nodePoolMaxNodesMetric.Reset()
+	collector.nodePoolCoresMetric.Reset()
+	collector.nodePoolRamMetric.Reset()
+	collector.nodePoolStorageMetric.Reset()
+	collector.dcK8sCoresMetric.Reset()
+	collector.dcK8sRamMetric.Reset()
+	collector.dcK8sNodesMetric.Reset()
+	collector.totalClustersMetric.Reset()
+
+	// Summed up before they are set, Add on the GaugeVecs would count twice if two scrapes overlap
+	dcCores := make(map[k8sDatacenterKey]float64)
+	dcRam := make(map[k8sDatacenterKey]float64)
+	dcNodes := make(map[k8sDatacenterKey]float64)
+	for account, clusters := range IonosK8sClusters {
+		for clusterName, cluster := range clusters {
+			collector.clusterStateMetric.WithLabelValues(account, clusterName, cluster.Id, cluster.K8sVersion, cluster.State).Set(1)
+			for _, nodePool := range cluster.NodePools {
+				nodes := float64(nodePool.ActualNodeCount)
+				if nodePool.NodesUnknown {
+					// Without the actual count the capacity is based on the desired count, so a failed
+					// API call does not look like a drop in capacity, the actual count itself is left out
+					nodes = float64(nodePool.NodeCount)
+				} else {
+					collector.nodePoolActualNodesMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(nodes)
+				}
+				collector.nodePoolStateMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName, nodePool.K8sVersion, nodePool.State).Set(1)
+				collector.nodePoolDesiredNodesMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(float64(nodePool.NodeCount))
+				collector.nodePoolMinNodesMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(float64(nodePool.MinNodeCount))
+				collector.nodePoolMaxNodesMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(float64(nodePool.MaxNodeCount))
+				collector.nodePoolCoresMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(nodes * float64(nodePool.Cores))
+				collector.nodePoolRamMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName).Set(nodes * float64(nodePool.Ram) / 1024) // MB -> GB
+				collector.nodePoolStorageMetric.WithLabelValues(account, clusterName, nodePool.Name, nodePool.Id, nodePool.DatacenterName, nodePool.StorageType).Set(nodes * float64(nodePool.Storage))
+
+				key := k8sDatacenterKey{account: account, datacenter: nodePool.DatacenterName}
+				dcCores[key] += nodes * float64(nodePool.Cores)
+				dcRam[key] += nodes * float64(nodePool.Ram) / 1024 // MB -> GB
+				dcNodes[key] += nodes
+			}
+		}
+		collector.totalClustersMetric.WithLabelValues(account).Set(float64(len(clusters)))
+	}
+	for key, nodes := range dcNodes {
+		collector.dcK8sCoresMetric.WithLabelValues(key.account, key.datacenter).Set(dcCores[key])
+		collector.dcK8sRamMetric.WithLabelValues(key.account, key.datacenter).Set(dcRam[key])
+		collector.dcK8sNodesMetric.WithLabelValues(key.account, key.datacenter).Set(nodes)
+	}
+
+	collector.clusterStateMetric.Collect(ch)
+	collector.nodePoolStateMetric.Collect(ch)
+	collector.nodePoolDesiredNodesMetric.Collect(ch)
+	collector.nodePoolActualNodesMetric.Collect(ch)
+	collector.nodePoolMinNodesMetric.Collect(ch)
+	collector.nodePoolMaxNodesMetric.Collect(ch)
+	collector.nodePoolCoresMetric.Collect(ch)
+	collector.nodePoolRamMetric.Collect(ch)
+	collector.nodePoolStorageMetric.Collect(ch)
+	collector.dcK8sCoresMetric.Collect(ch)
+	collector.dcK8sRamMetric.Collect(ch)
+	collector.dcK8sNodesMetric.Collect(ch)
+	collector.totalClustersMetric.Collect(ch)
+}
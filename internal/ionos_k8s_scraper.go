@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+var (
+	IonosK8sClusters = make(map[string]map[string]IonosK8sClusterResources) //Key is the name of the account, then the name of the kubernetes cluster
+)
+
+type IonosK8sClusterResources struct {
+	Id         string                      // UUID of the cluster
+	K8sVersion string                      // Kubernetes version of the control plane
+	State      string                      // State of the cluster, e.g. ACTIVE or DEPLOYING
+	NodePools  []IonosK8sNodePoolResources // Node pools belonging to the cluster
+}
+
+type IonosK8sNodePoolResources struct {
+	Id               string // UUID of the node pool
+	Name             string // Name of the node pool
+	DatacenterId     string // UUID of the datacenter the nodes are running in
+	DatacenterName   string // Name of the datacenter the nodes are running in
+	K8sVersion       string // Kubernetes version of the nodes
+	State            string // State of the node pool, e.g. ACTIVE or UPDATING
+	CpuFamily        string // CPU family of the nodes
+	AvailabilityZone string // Availability zone of the nodes
+	StorageType      string // Storage type of the nodes, HDD or SSD
+	NodeCount        int32  // Desired number of nodes
+	ActualNodeCount  int32  // Number of nodes which currently exist in the node pool
+	NodesUnknown     bool   // The nodes could not be retrieved, ActualNodeCount is not set
+	MinNodeCount     int32  // Minimum number of nodes if autoscaling is enabled
+	MaxNodeCount     int32  // Maximum number of nodes if autoscaling is enabled
+	Cores            int32  // Cores per node
+	Ram              int32  // RAM per node in MB
+	Storage          int32  // Storage per node in GB
+}
+
+func K8sCollectResources(m *sync.RWMutex, account AccountConfig, config CollectorConfig) {
+	cfgENV := account.IonosConfiguration()
+	apiClient := ionoscloud.NewAPIClient(cfgENV)
+
+	config.Run("k8s", account, func(ctx context.Context) {
+		clusters, resp, err := apiClient.KubernetesApi.K8sGet(ctx).Depth(1).Execute()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error when calling `KubernetesApi.K8sGet``: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+			return
+		}
+		if clusters.Items == nil {
+			fmt.Fprintf(os.Stderr, "Kubernetes clusters are nil\n")
+			return
+		}
+		datacenterNames, err := fetchDatacenterNames(ctx, apiClient)
+		if err != nil {
+			fmt.Printf("Error retrieving datacenter names: %v\n", err)
+		}
+
+		newIonosK8sClusters := make(map[string]IonosK8sClusterResources)
+		for _, cluster := range *clusters.Items {
+			if cluster.Id == nil || cluster.Properties == nil || cluster.Properties.Name == nil {
+				fmt.Fprintf(os.Stderr, "Kubernetes cluster is missing necessary fields, skip\n")
+				continue
+			}
+			nodePools, err := fetchK8sNodePools(ctx, apiClient, *cluster.Id)
+			if err != nil {
+				fmt.Printf("Error retrieving node pools for kubernetes cluster %s: %v\n", *cluster.Properties.Name, err)
+				continue
+			}
+			clusterResources := IonosK8sClusterResources{
+				Id:         *cluster.Id,
+				K8sVersion: ionoscloud.ToValueDefault(cluster.Properties.K8sVersion),
+				NodePools:  processK8sNodePools(ctx, apiClient, *cluster.Id, nodePools, datacenterNames),
+			}
+			if cluster.Metadata != nil {
+				clusterResources.State = ionoscloud.ToValueDefault(cluster.Metadata.State)
+			}
+			newIonosK8sClusters[*cluster.Properties.Name] = clusterResources
+		}
+
+		// A run which timed out is incomplete, the previous snapshot is kept instead
+		if ctx.Err() != nil {
+			fmt.Printf("The kubernetes scrape of account %s was cancelled, keeping the previous data: %v\n", account.Name, ctx.Err())
+			return
+		}
+		m.Lock()
+		IonosK8sClusters[account.Name] = newIonosK8sClusters
+		m.Unlock()
+	})
+}
+
+/*
+Retrieves the names of all datacenters of the account, used to attribute resources to the datacenter they run in
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+
+Returns:
+  - map[string]string: datacenter names keyed by the datacenter UUID
+  - error: An error if there was an issue making the API call
+*/
+func fetchDatacenterNames(ctx context.Context, apiClient *ionoscloud.APIClient) (map[string]string, error) {
+	datacenterNames := make(map[string]string)
+	datacenters, resp, err := apiClient.DataCentersApi.DatacentersGet(ctx).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling DataCenters API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return datacenterNames, err
+	}
+
+	if datacenters.Items == nil {
+		return datacenterNames, fmt.Errorf("no items in resource")
+	}
+	for _, datacenter := range *datacenters.Items {
+		if datacenter.Id != nil && datacenter.Properties != nil && datacenter.Properties.Name != nil {
+			datacenterNames[*datacenter.Id] = *datacenter.Properties.Name
+		}
+	}
+	return datacenterNames, nil
+}
+
+/*
+Retrieves the node pools of a kubernetes cluster using the ionoscloud API Client
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+  - clusterId: UUID of the kubernetes cluster
+
+Returns:
+  - *ionoscloud.KubernetesNodePools: A pointer to the node pools of the cluster or an error if it fails
+*/
+func fetchK8sNodePools(ctx context.Context, apiClient *ionoscloud.APIClient, clusterId string) (*ionoscloud.KubernetesNodePools, error) {
+	nodePools, resp, err := apiClient.KubernetesApi.K8sNodepoolsGet(ctx, clusterId).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling Kubernetes NodePools API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if nodePools.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &nodePools, nil
+}
+
+/*
+Retrieves the nodes of a kubernetes node pool using the ionoscloud API Client
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+  - clusterId: UUID of the kubernetes cluster
+  - nodePoolId: UUID of the node pool
+
+Returns:
+  - *ionoscloud.KubernetesNodes: A pointer to the nodes of the node pool or an error if it fails
+*/
+func fetchK8sNodes(ctx context.Context, apiClient *ionoscloud.APIClient, clusterId string, nodePoolId string) (*ionoscloud.KubernetesNodes, error) {
+	nodes, resp, err := apiClient.KubernetesApi.K8sNodepoolsNodesGet(ctx, clusterId, nodePoolId).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling Kubernetes Nodes API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if nodes.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &nodes, nil
+}
+
+/*
+Extracts node counts, autoscaling limits and per node capacity of the node pools of a kubernetes cluster
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient, used to count the actual nodes of each node pool
+  - clusterId: UUID of the kubernetes cluster
+  - nodePools: A pointer to ionoscloud.KubernetesNodePools containing the node pools to process
+  - datacenterNames: datacenter names keyed by UUID, used to attribute the node pool to its datacenter
+
+Returns:
+  - []IonosK8sNodePoolResources: the details of every node pool
+
+If the nodes of a node pool can not be retrieved, the node pool is marked with NodesUnknown.
+*/
+func processK8sNodePools(ctx context.Context, apiClient *ionoscloud.APIClient, clusterId string, nodePools *ionoscloud.KubernetesNodePools, datacenterNames map[string]string) []IonosK8sNodePoolResources {
+	nodePoolDetails := make([]IonosK8sNodePoolResources, 0, len(*nodePools.Items))
+	for _, nodePool := range *nodePools.Items {
+		if nodePool.Id == nil || nodePool.Properties == nil {
+			fmt.Println("NodePool Id or NodePool Properties are nil")
+			continue
+		}
+		properties := nodePool.Properties
+		nodePoolDetail := IonosK8sNodePoolResources{
+			Id:               *nodePool.Id,
+			Name:             ionoscloud.ToValueDefault(properties.Name),
+			DatacenterId:     ionoscloud.ToValueDefault(properties.DatacenterId),
+			K8sVersion:       ionoscloud.ToValueDefault(properties.K8sVersion),
+			CpuFamily:        ionoscloud.ToValueDefault(properties.CpuFamily),
+			AvailabilityZone: ionoscloud.ToValueDefault(properties.AvailabilityZone),
+			StorageType:      ionoscloud.ToValueDefault(properties.StorageType),
+			NodeCount:        ionoscloud.ToValueDefault(properties.NodeCount),
+			Cores:            ionoscloud.ToValueDefault(properties.CoresCount),
+			Ram:              ionoscloud.ToValueDefault(properties.RamSize),
+			Storage:          ionoscloud.ToValueDefault(properties.StorageSize),
+		}
+		if name, ok := datacenterNames[nodePoolDetail.DatacenterId]; ok {
+			nodePoolDetail.DatacenterName = name
+		} else {
+			nodePoolDetail.DatacenterName = nodePoolDetail.DatacenterId
+		}
+		if nodePool.Metadata != nil {
+			nodePoolDetail.State = ionoscloud.ToValueDefault(nodePool.Metadata.State)
+		}
+		if properties.AutoScaling != nil {
+			nodePoolDetail.MinNodeCount = ionoscloud.ToValueDefault(properties.AutoScaling.MinNodeCount)
+			nodePoolDetail.MaxNodeCount = ionoscloud.ToValueDefault(properties.AutoScaling.MaxNodeCount)
+		}
+		nodes, err := fetchK8sNodes(ctx, apiClient, clusterId, *nodePool.Id)
+		if err != nil {
+			fmt.Printf("Error retrieving nodes for node pool %s: %v\n", nodePoolDetail.Name, err)
+			nodePoolDetail.NodesUnknown = true
+		} else {
+			nodePoolDetail.ActualNodeCount = int32(len(*nodes.Items))
+		}
+		nodePoolDetails = append(nodePoolDetails, nodePoolDetail)
+	}
+	return nodePoolDetails
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type networkCollector struct {
+	mutex                        *sync.RWMutex
+	dcLansMetric                 *prometheus.GaugeVec
+	lanNicsMetric                *prometheus.GaugeVec
+	nicFirewallActiveMetric      *prometheus.GaugeVec
+	nicFirewallRulesMetric       *prometheus.GaugeVec
+	dcPublicNicsNoFirewallMetric *prometheus.GaugeVec
+}
+
+func NewNetworkCollector(m *sync.RWMutex) *networkCollector {
+	return &networkCollector{
+		mutex: m,
+		dcLansMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_dc_lans_amount",
+			Help: "Shows the number of public and private LANs in an IONOS datacenter",
+		}, []string{"account", "datacenter", "public"}),
+		lanNicsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_lan_nics_amount",
+			Help: "Shows the number of server NICs connected to a LAN",
+		}, []string{"account", "datacenter", "lan_id", "lan_name", "public"}),
+		nicFirewallActiveMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nic_firewall_active",
+			Help: "Shows whether the firewall of a server NIC is active (1) or not (0)",
+		}, []string{"account", "datacenter", "server", "server_id", "nic_id", "nic_name", "lan_id", "public"}),
+		nicFirewallRulesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_nic_firewall_rules_amount",
+			Help: "Shows the number of firewall rules of a server NIC per protocol and direction",
+		}, []string{"account", "datacenter", "server", "server_id", "nic_id", "nic_name", "protocol", "direction"}),
+		dcPublicNicsNoFirewallMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_dc_public_nics_without_firewall_amount",
+			Help: "Shows the number of NICs in a public LAN without active firewall in an IONOS datacenter",
+		}, []string{"account", "datacenter"}),
+	}
+}
+
+func (collector *networkCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.dcLansMetric.Describe(ch)
+	collector.lanNicsMetric.Describe(ch)
+	collector.nicFirewallActiveMetric.Describe(ch)
+	collector.nicFirewallRulesMetric.Describe(ch)
+	collector.dcPublicNicsNoFirewallMetric.Describe(ch)
+}
+
+func (collector *networkCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+
+	// Reset metrics in case a LAN or NIC was removed
+	collector.dcLansMetric.Reset()
+	collector.lanNicsMetric.Reset()
+	collector.nicFirewallActiveMetric.Reset()
+	collector.nicFirewallRulesMetric.Reset()
+	collector.dcPublicNicsNoFirewallMetric.Reset()
+
+	for account, datacenters := range IonosDatacenters {
+		for dcName, dcResources := range datacenters {
+			if dcResources.LANDetails == nil {
+				continue
+			}
+			nicsPerLan := make(map[string]int)
+			publicNicsNoFirewall := 0
+			for _, nic := range dcResources.NICDetails {
+				nicsPerLan[nic.LanId]++
+				firewallActive := 0.0
+				if nic.FirewallActive {
+					firewallActive = 1
+				} else if nic.Public {
+					publicNicsNoFirewall++
+				}
+				collector.nicFirewallActiveMetric.WithLabelValues(account, dcName, nic.ServerName, nic.ServerId, nic.Id, nic.Name, nic.LanId, strconv.FormatBool(nic.Public)).Set(firewallActive)
+				for rule, amount := range nic.FirewallRules {
+					collector.nicFirewallRulesMetric.WithLabelValues(account, dcName, nic.ServerName, nic.ServerId, nic.Id, nic.Name, rule.Protocol, rule.Direction).Set(float64(amount))
+				}
+			}
+
+			collector.dcLansMetric.WithLabelValues(account, dcName, "true").Set(0)
+			collector.dcLansMetric.WithLabelValues(account, dcName, "false").Set(0)
+			for _, lan := range dcResources.LANDetails {
+				public := strconv.FormatBool(lan.Public)
+				collector.dcLansMetric.WithLabelValues(account, dcName, public).Inc()
+				collector.lanNicsMetric.WithLabelValues(account, dcName, lan.Id, lan.Name, public).Set(float64(nicsPerLan[lan.Id]))
+			}
+			collector.dcPublicNicsNoFirewallMetric.WithLabelValues(account, dcName).Set(float64(publicNicsNoFirewall))
+		}
+	}
+
+	collector.dcLansMetric.Collect(ch)
+	collector.lanNicsMetric.Collect(ch)
+	collector.nicFirewallActiveMetric.Collect(ch)
+	collector.nicFirewallRulesMetric.Collect(ch)
+	collector.dcPublicNicsNoFirewallMetric.Collect(ch)
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+type IonosLANResources struct {
+	Id     string // ID of the LAN
+	Name   string // Name of the LAN
+	Public bool   // Whether the LAN is connected to the internet
+}
+
+type IonosNICResources struct {
+	Id             string                         // UUID of the NIC
+	Name           string                         // Name of the NIC
+	ServerId       string                         // UUID of the server the NIC belongs to
+	ServerName     string                         // Name of the server the NIC belongs to
+	LanId          string                         // ID of the LAN the NIC is connected to
+	Public         bool                           // Whether the LAN of the NIC is public
+	FirewallActive bool                           // Whether the firewall of the NIC is active
+	FirewallRules  map[IonosFirewallRuleKey]int32 // Number of firewall rules per protocol and direction
+}
+
+type IonosFirewallRuleKey struct {
+	Protocol  string // Protocol of the rule, e.g. TCP, UDP, ICMP or ANY
+	Direction string // Direction of the rule, INGRESS or EGRESS
+}
+
+/*
+Retrieves a list of LANs which are associated with specific datacenter using the ionoscloud API Client
+
+Parameters:
+ctx: context of the collection run, cancelled on timeout
+apiClient: An instance of APIClient for making API Requests
+datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
+
+Returns:
+- *ionoscloud.Lans: A pointer to ionoscloud.Lans which has the LAN list or an error if it fails
+*/
+func fetchLANs(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.Lans, error) {
+	datacenterId := *datacenter.Id
+	lans, resp, err := apiClient.LANsApi.DatacentersLansGet(ctx, datacenterId).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling LANs API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if lans.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &lans, nil
+}
+
+/*
+Retrieves the NICs of a server including their firewall rules using the ionoscloud API Client
+
+Parameters:
+ctx: context of the collection run, cancelled on timeout
+apiClient: An instance of APIClient for making API Requests
+datacenterId: UUID of the datacenter the server belongs to
+serverId: UUID of the server
+
+Returns:
+- *ionoscloud.Nics: A pointer to ionoscloud.Nics which has the NIC list or an error if it fails
+*/
+func fetchServerNICs(ctx context.Context, apiClient *ionoscloud.APIClient, datacenterId string, serverId string) (*ionoscloud.Nics, error) {
+	nics, resp, err := apiClient.NetworkInterfacesApi.DatacentersServersNicsGet(ctx, datacenterId, serverId).Depth(3).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling NetworkInterfaces API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if nics.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &nics, nil
+}
+
+/*
+Walks the LANs and the NICs of all servers of a datacenter
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+  - datacenter: Pointer to an ionoscloud.Datacenter object representing the target datacenter.
+  - servers: the already processed servers of the datacenter
+
+Returns:
+  - []IonosLANResources: ID, name and visibility of every LAN
+  - []IonosNICResources: LAN, firewall state and firewall rules of every NIC
+  - error: An error if the LANs could not be retrieved
+
+Servers whose NICs can not be retrieved are skipped.
+*/
+func processNetworkTopology(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter, servers []IonosServerResources) ([]IonosLANResources, []IonosNICResources, error) {
+	lans, err := fetchLANs(ctx, apiClient, datacenter)
+	if err != nil {
+		return nil, nil, err
+	}
+	lanDetails := make([]IonosLANResources, 0, len(*lans.Items))
+	publicLans := make(map[string]bool)
+	for _, lan := range *lans.Items {
+		if lan.Id == nil || lan.Properties == nil {
+			continue
+		}
+		lanDetail := IonosLANResources{
+			Id:     *lan.Id,
+			Name:   ionoscloud.ToValueDefault(lan.Properties.Name),
+			Public: ionoscloud.ToValueDefault(lan.Properties.Public),
+		}
+		publicLans[lanDetail.Id] = lanDetail.Public
+		lanDetails = append(lanDetails, lanDetail)
+	}
+
+	var nicDetails []IonosNICResources
+	for _, server := range servers {
+		nics, err := fetchServerNICs(ctx, apiClient, *datacenter.Id, server.Id)
+		if err != nil {
+			fmt.Printf("Error retrieving NICs for server %s: %v\n", server.Name, err)
+			continue
+		}
+		for _, nic := range *nics.Items {
+			if nic.Id == nil || nic.Properties == nil {
+				continue
+			}
+			nicDetail := IonosNICResources{
+				Id:             *nic.Id,
+				Name:           ionoscloud.ToValueDefault(nic.Properties.Name),
+				ServerId:       server.Id,
+				ServerName:     server.Name,
+				LanId:          fmt.Sprintf("%d", ionoscloud.ToValueDefault(nic.Properties.Lan)),
+				FirewallActive: ionoscloud.ToValueDefault(nic.Properties.FirewallActive),
+				FirewallRules:  make(map[IonosFirewallRuleKey]int32),
+			}
+			nicDetail.Public = publicLans[nicDetail.LanId]
+			if nic.Entities != nil && nic.Entities.Firewallrules != nil && nic.Entities.Firewallrules.Items != nil {
+				for _, rule := range *nic.Entities.Firewallrules.Items {
+					if rule.Properties == nil {
+						continue
+					}
+					direction := ionoscloud.ToValueDefault(rule.Properties.Type)
+					if direction == "" {
+						// The API defaults to INGRESS if no type is given
+						direction = "INGRESS"
+					}
+					nicDetail.FirewallRules[IonosFirewallRuleKey{
+						Protocol:  ionoscloud.ToValueDefault(rule.Properties.Protocol),
+						Direction: direction,
+					}]++
+				}
+			}
+			nicDetails = append(nicDetails, nicDetail)
+		}
+	}
+	return lanDetails, nicDetails, nil
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type requestsCollector struct {
+	mutex                *sync.RWMutex
+	requestsMetric       *prometheus.GaugeVec
+	oldestInFlightMetric *prometheus.GaugeVec
+	windowMetric         *prometheus.GaugeVec
+}
+
+func NewRequestsCollector(m *sync.RWMutex) *requestsCollector {
+	return &requestsCollector{
+		mutex: m,
+		requestsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_api_requests_amount",
+			Help: "Shows the number of asynchronous provisioning requests created within the sliding window per status and HTTP method",
+		}, []string{"account", "status", "method"}),
+		oldestInFlightMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_api_oldest_inflight_request_seconds",
+			Help: "Shows the time since the oldest queued or running provisioning request was created in seconds, 0 if there is none",
+		}, []string{"account"}),
+		windowMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_api_requests_window_seconds",
+			Help: "Shows the length of the sliding window the provisioning requests are counted in",
+		}, []string{"account"}),
+	}
+}
+
+func (collector *requestsCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.requestsMetric.Describe(ch)
+	collector.oldestInFlightMetric.Describe(ch)
+	collector.windowMetric.Describe(ch)
+}
+
+func (collector *requestsCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+
+	// Reset metrics in case a status or method is no longer in the sliding window
+	collector.requestsMetric.Reset()
+	collector.oldestInFlightMetric.Reset()
+	collector.windowMetric.Reset()
+
+	for account, requests := range IonosRequests {
+		for key, amount := range requests.Amounts {
+			collector.requestsMetric.WithLabelValues(account, key.Status, key.Method).Set(float64(amount))
+		}
+		if requests.OldestInFlight.IsZero() {
+			collector.oldestInFlightMetric.WithLabelValues(account).Set(0)
+		} else {
+			collector.oldestInFlightMetric.WithLabelValues(account).Set(time.Since(requests.OldestInFlight).Seconds())
+		}
+		collector.windowMetric.WithLabelValues(account).Set(requests.Window.Seconds())
+	}
+
+	collector.requestsMetric.Collect(ch)
+	collector.oldestInFlightMetric.Collect(ch)
+	collector.windowMetric.Collect(ch)
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+var (
+	IonosRequests = make(map[string]IonosRequestResources) //Key is the name of the account
+)
+
+type IonosRequestResources struct {
+	Amounts        map[IonosRequestKey]int32 // Number of provisioning requests in the sliding window per status and method
+	OldestInFlight time.Time                 // Creation time of the oldest queued or running request, zero if there is none
+	Window         time.Duration             // Length of the sliding window
+}
+
+type IonosRequestKey struct {
+	Status string // Status of the request, QUEUED, RUNNING, DONE or FAILED
+	Method string // HTTP method of the request, e.g. POST or DELETE
+}
+
+const requestsPerPage = 1000
+
+func RequestsCollectResources(m *sync.RWMutex, account AccountConfig, config RequestsCollectorConfig) {
+	cfgENV := account.IonosConfiguration()
+	apiClient := ionoscloud.NewAPIClient(cfgENV)
+
+	config.Run("requests", account, func(ctx context.Context) {
+		requests, err := fetchRequests(ctx, apiClient, time.Now().Add(-config.Window), "")
+		if err != nil {
+			fmt.Printf("Error retrieving provisioning requests: %v\n", err)
+			return
+		}
+		// Requests which are stuck for longer than the window are not part of it, so they are queried separately
+		var inFlight []ionoscloud.Request
+		for _, status := range []string{"QUEUED", "RUNNING"} {
+			requests, err := fetchRequests(ctx, apiClient, time.Time{}, status)
+			if err != nil {
+				fmt.Printf("Error retrieving %s provisioning requests: %v\n", status, err)
+				return
+			}
+			inFlight = append(inFlight, requests...)
+		}
+		newIonosRequests := processRequests(requests, inFlight)
+		newIonosRequests.Window = config.Window
+		m.Lock()
+		IonosRequests[account.Name] = newIonosRequests
+		m.Unlock()
+	})
+}
+
+/*
+Retrieves all provisioning requests created after a given time or with a given status using the ionoscloud API Client
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+  - createdAfter: start of the sliding window, the creation time is not filtered if it is zero
+  - status: status of the requests, e.g. QUEUED, the status is not filtered if it is empty
+
+Returns:
+  - []ionoscloud.Request: the requests of all pages, or an error if any page could not be retrieved
+*/
+func fetchRequests(ctx context.Context, apiClient *ionoscloud.APIClient, createdAfter time.Time, status string) ([]ionoscloud.Request, error) {
+	var allRequests []ionoscloud.Request
+	for offset := int32(0); ; offset += requestsPerPage {
+		request := apiClient.RequestsApi.RequestsGet(ctx).Depth(2)
+		if !createdAfter.IsZero() {
+			request = request.FilterCreatedAfter(createdAfter.UTC().Format("2006-01-02 15:04:05"))
+		}
+		if status != "" {
+			request = request.FilterStatus(status)
+		}
+		requests, resp, err := request.Offset(offset).Limit(requestsPerPage).Execute()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error when calling Requests API: %v\n", err)
+			if resp != nil {
+				fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+			} else {
+				fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+			}
+			return nil, err
+		}
+		if requests.Items == nil {
+			return nil, fmt.Errorf("no items in resource")
+		}
+		allRequests = append(allRequests, *requests.Items...)
+		if len(*requests.Items) < requestsPerPage {
+			return allRequests, nil
+		}
+	}
+}
+
+/*
+Counts provisioning requests per status and HTTP method and finds the oldest request which is still in flight
+
+Parameters:
+  - requests: the provisioning requests of the sliding window
+  - inFlight: all queued and running provisioning requests, regardless of the sliding window
+
+Returns:
+  - IonosRequestResources: the counted requests and the creation time of the oldest queued or running request
+*/
+func processRequests(requests []ionoscloud.Request, inFlight []ionoscloud.Request) IonosRequestResources {
+	requestDetails := IonosRequestResources{Amounts: make(map[IonosRequestKey]int32)}
+	for _, request := range requests {
+		if request.Metadata == nil || request.Properties == nil {
+			continue
+		}
+		key := IonosRequestKey{Method: ionoscloud.ToValueDefault(request.Properties.Method)}
+		if request.Metadata.RequestStatus != nil && request.Metadata.RequestStatus.Metadata != nil {
+			key.Status = ionoscloud.ToValueDefault(request.Metadata.RequestStatus.Metadata.Status)
+		}
+		requestDetails.Amounts[key]++
+	}
+	for _, request := range inFlight {
+		if request.Metadata == nil || request.Metadata.CreatedDate == nil {
+			continue
+		}
+		createdDate := request.Metadata.CreatedDate.Time
+		if requestDetails.OldestInFlight.IsZero() || createdDate.Before(requestDetails.OldestInFlight) {
+			requestDetails.OldestInFlight = createdDate
+		}
+	}
+	return requestDetails
+}
@@ -5,57 +5,134 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"sync"
-	"time"
 
 	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
 )
 
 var (
-	CoresTotal       int32 = 0
-	RamTotal         int32 = 0
-	ServerTotal      int32 = 0
-	DataCenters      int32 = 0
-	IonosDatacenters       = make(map[string]IonosDCResources) //Key is the name of the datacenter
-	depth            int32 = 1
+	IonosTotals             = make(map[string]IonosTotalResources)                  //Key is the name of the account
+	IonosDatacenters        = make(map[string]map[string]IonosDCResources)          //Key is the name of the account, then the name of the datacenter
+	IonosTargetGroups       = make(map[string]map[string]IonosTargetGroupResources) //Key is the name of the account, then the UUID of the target group
+	IonosIPBlocks           = make(map[string]map[string]IonosIPBlockResources)     //Key is the name of the account, then the UUID of the IP block
+	depth             int32 = 1
 )
 
+type IonosTotalResources struct {
+	Cores       int32 // Amount of CPU cores in all datacenters of the account
+	Ram         int32 // Amount of RAM in all datacenters of the account
+	Servers     int32 // Amount of servers in all datacenters of the account
+	DataCenters int32 // Amount of datacenters of the account
+}
+
 type IonosDCResources struct {
-	Cores                int32  // Amount of CPU cores in the whole DC, regardless whether it is a VM or Kubernetscluster
-	Ram                  int32  // Amount of RAM in the whole DC, regardless whether it is a VM or Kubernetscluster
-	Servers              int32  // Amount of servers in the whole DC
-	DCId                 string // UUID od the datacenter
-	NLBs                 int32  //Number of Networkloadbalancers
-	ALBs                 int32  //Number of Applicationloadbalanceers
-	NATs                 int32  //Number of NAT Gateways
-	NLBRules             int32  //Number of NLB Rules
-	ALBRules             int32  //Number of ALB Rueles
-	ALBName              string //ALB Name
-	NLBName              string //NLB Name
-	NLBRuleName          string //Rule name of NLB
-	ALBRuleName          string //Rule name of ALB
-	IPName               string //IP Name
-	TotalIPs             int32  //Number of total IP-s
+	Cores                int32                        // Amount of CPU cores in the whole DC, regardless whether it is a VM or Kubernetscluster
+	Ram                  int32                        // Amount of RAM in the whole DC, regardless whether it is a VM or Kubernetscluster
+	Servers              int32                        // Amount of servers in the whole DC
+	DCId                 string                       // UUID od the datacenter
+	Location             string                       // Location of the datacenter, e.g. de/fra
+	NLBs                 int32                        //Number of Networkloadbalancers
+	ALBs                 int32                        //Number of Applicationloadbalanceers
+	NATs                 int32                        //Number of NAT Gateways
+	NLBRules             int32                        //Number of NLB Rules
+	ALBRules             int32                        //Number of ALB Rueles
+	NLBDetails           []IonosLoadBalancerResources // Per NLB details including forwarding rules
+	ALBDetails           []IonosLoadBalancerResources // Per ALB details including forwarding rules
+	NATDetails           []IonosNATGatewayResources   // Per NAT Gateway details including rules and LANs
 	TotalAPICallFailures int32
+	ServerDetails        []IonosServerResources // Per server details of all servers in the DC
+	LANDetails           []IonosLANResources    // Per LAN details, only if the network topology is enabled
+	NICDetails           []IonosNICResources    // Per NIC details including firewall rules, only if the network topology is enabled
+}
+
+type IonosLoadBalancerResources struct {
+	Id          string                         // UUID of the loadbalancer
+	Name        string                         // Name of the loadbalancer
+	ListenerIps string                         // Comma separated list of the listener IPs
+	State       string                         // Provisioning state of the loadbalancer, e.g. AVAILABLE or BUSY
+	Rules       []IonosForwardingRuleResources // Forwarding rules of the loadbalancer
+}
+
+type IonosForwardingRuleResources struct {
+	Id           string   // UUID of the forwarding rule
+	Name         string   // Name of the forwarding rule
+	Protocol     string   // Protocol of the forwarding rule, e.g. TCP or HTTP
+	ListenerPort int32    // Port the loadbalancer listens on for this rule
+	Targets      int32    // NLB: number of targets, ALB: number of target groups referenced by the HTTP rules
+	TargetGroups []string // ALB only: UUIDs of the target groups referenced by the HTTP rules
+}
+
+type IonosNATGatewayResources struct {
+	Id        string                        // UUID of the NAT gateway
+	Name      string                        // Name of the NAT gateway
+	State     string                        // Provisioning state of the NAT gateway, e.g. AVAILABLE or BUSY
+	PublicIps []string                      // Public IPs of the NAT gateway
+	SNATRules int32                         // Number of SNAT rules
+	Lans      []IonosNATGatewayLanResources // LANs the NAT gateway is connected to
+}
+
+type IonosNATGatewayLanResources struct {
+	Id         int32  // ID of the connected LAN
+	GatewayIps string // Comma separated list of the gateway IPs in the LAN
 }
 
-func CollectResources(m *sync.RWMutex, cycletime int32) {
+type IonosTargetGroupResources struct {
+	Name                      string // Name of the target group
+	Algorithm                 string // Balancing algorithm, e.g. ROUND_ROBIN
+	Protocol                  string // Forwarding protocol, e.g. HTTP
+	Targets                   int32  // Number of targets in the target group
+	HealthCheckEnabledTargets int32  // Number of targets with enabled health check
+	CheckInterval             int32  // Interval between health checks in milliseconds
+	CheckTimeout              int32  // Timeout of a health check in milliseconds
+	Retries                   int32  // Number of retries before a target is considered unhealthy
+	HttpHealthCheckPath       string // Path of the HTTP health check
+	HttpHealthCheckMethod     string // Method of the HTTP health check
+	HttpHealthCheckMatchType  string // Match type of the HTTP health check response, STATUS_CODE or RESPONSE_BODY
+	HttpHealthCheckResponse   string // Expected response of the HTTP health check
+	References                int32  // Number of ALB forwarding rules which reference the target group
+	ReferencesUnknown         bool   // The ALBs of a datacenter could not be retrieved and there is no previous value, References is not set
+}
 
-	cfgENV := ionoscloud.NewConfigurationFromEnv()
+type IonosIPBlockResources struct {
+	Name     string           // Name of the IP block
+	Location string           // Location the IP block is reserved in, e.g. de/fra
+	Size     int32            // Number of reserved IPs in the IP block
+	UsedIps  map[string]int32 // Number of IPs assigned to a consumer, keyed by the consumer type (nic, loadbalancer, natgateway, k8s, other)
+}
+
+type IonosServerResources struct {
+	Id               string // UUID of the server
+	Name             string // Name of the server
+	Cores            int32  // Amount of CPU cores of the server
+	Ram              int32  // Amount of RAM of the server in MB
+	CpuFamily        string // CPU family of the server, e.g. INTEL_SKYLAKE
+	Type             string // Server type, ENTERPRISE or CUBE
+	AvailabilityZone string // Availability zone the server is placed in
+	VmState          string // State of the virtual machine, e.g. RUNNING or SHUTOFF
+}
+
+func CollectResources(m *sync.RWMutex, account AccountConfig, config DatacenterCollectorConfig) {
+
+	cfgENV := account.IonosConfiguration()
 
 	cfgENV.Debug = false
 	apiClient := ionoscloud.NewAPIClient(cfgENV)
 
 	totalAPICallFailures := 0
-	for {
-		datacenters, resp, err := apiClient.DataCentersApi.DatacentersGet(context.Background()).Depth(depth).Execute()
+	config.Run("datacenter", account, func(ctx context.Context) {
+		datacenters, resp, err := apiClient.DataCentersApi.DatacentersGet(ctx).Depth(depth).Execute()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error when calling `DataCentersApi.DatacentersGet``: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
 			totalAPICallFailures++
-			continue
+			return
 		}
 		newIonosDatacenters := make(map[string]IonosDCResources)
+		loadBalancerIps := make(map[string]bool)
+		natGatewayIps := make(map[string]bool)
+		// A skipped datacenter is missing its ALBs, so the target group references can not be counted
+		albsComplete := true
 		for _, datacenter := range *datacenters.Items {
 			var (
 				coresTotalDC         int32 = 0
@@ -66,58 +143,86 @@ func CollectResources(m *sync.RWMutex, cycletime int32) {
 				albTotalRulesDC      int32 = 0
 				albTotalDC           int32 = 0
 				natTotalDC           int32 = 0
-				albNames             string
-				nlbNames             string
-				albRuleNames         string
-				nlbRuleNames         string
-				totalIPs             int32 = 0
+				albDetails           []IonosLoadBalancerResources
+				nlbDetails           []IonosLoadBalancerResources
+				natDetails           []IonosNATGatewayResources
+				lanDetails           []IonosLANResources
+				nicDetails           []IonosNICResources
 				totalAPICallFailures int32 = 0
 			)
-			servers, resp, err := apiClient.ServersApi.DatacentersServersGet(context.Background(), *datacenter.Id).Depth(depth).Execute()
+			servers, resp, err := apiClient.ServersApi.DatacentersServersGet(ctx, *datacenter.Id).Depth(depth).Execute()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error when calling `ServersApi.DatacentersServersGet``: %v\n", err)
 				fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
 				totalAPICallFailures++
+				albsComplete = false
 				continue
 			}
 
-			albList, err := fetchApplicationLoadbalancers(apiClient, &datacenter)
+			albList, err := fetchApplicationLoadbalancers(ctx, apiClient, &datacenter)
 			if err != nil {
 				fmt.Printf("Error retrieving ALBs for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				albsComplete = false
 				continue
 			}
-			nlbList, err := fetchNetworkLoadBalancers(apiClient, &datacenter)
+			nlbList, err := fetchNetworkLoadBalancers(ctx, apiClient, &datacenter)
 			if err != nil {
 				fmt.Printf("Error retrieving NLBs for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				albsComplete = false
 				continue
 			}
-			natList, err := fetchNATGateways(apiClient, &datacenter)
+			natList, err := fetchNATGateways(ctx, apiClient, &datacenter)
 			if err != nil {
 				fmt.Printf("Error retrieving NATs for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				albsComplete = false
 				continue
 			}
-			ipBlocks, err := fetchIPBlocks(apiClient)
-			if err != nil {
-				fmt.Printf("Error retrieving IPs for datacenter %s: %v\n", *datacenter.Properties.Name, err)
-				continue
-			}
 
-			totalIPs = processIPBlocks(ipBlocks)
-			nlbNames, nlbTotalRulesDC = processNetworkLoadBalancers(nlbList)
-			albNames, albTotalRulesDC = processApplicationLoadBalancers(albList)
+			nlbDetails, nlbTotalRulesDC = processNetworkLoadBalancers(nlbList)
+			albDetails, albTotalRulesDC = processApplicationLoadBalancers(albList)
+			natDetails = processNATGateways(natList)
+
+			for _, nlb := range *nlbList.Items {
+				if nlb.Properties != nil {
+					for _, ip := range ionoscloud.ToValueDefault(nlb.Properties.Ips) {
+						loadBalancerIps[ip] = true
+					}
+				}
+			}
+			for _, alb := range *albList.Items {
+				if alb.Properties != nil {
+					for _, ip := range ionoscloud.ToValueDefault(alb.Properties.Ips) {
+						loadBalancerIps[ip] = true
+					}
+				}
+			}
+			for _, nat := range natDetails {
+				for _, ip := range nat.PublicIps {
+					natGatewayIps[ip] = true
+				}
+			}
 
 			nlbTotalDC = int32(len(*nlbList.Items))
 			albTotalDC = int32(len(*albList.Items))
 			natTotalDC = int32(len(*natList.Items))
 			serverTotalDC = int32(len(*servers.Items))
 
-			for _, server := range *servers.Items {
-				coresTotalDC += *server.Properties.Cores
-				ramTotalDC += *server.Properties.Ram
+			serverDetails := processServers(&servers)
+			for _, server := range serverDetails {
+				coresTotalDC += server.Cores
+				ramTotalDC += server.Ram
+			}
+
+			if config.NetworkTopology {
+				lanDetails, nicDetails, err = processNetworkTopology(ctx, apiClient, &datacenter, serverDetails)
+				if err != nil {
+					fmt.Printf("Error retrieving LANs for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				}
 			}
 
 			newIonosDatacenters[*datacenter.Properties.Name] = IonosDCResources{
 				DCId:                 *datacenter.Id,
+				Location:             ionoscloud.ToValueDefault(datacenter.Properties.Location),
 				Cores:                coresTotalDC,
 				Ram:                  ramTotalDC,
 				Servers:              serverTotalDC,
@@ -126,69 +231,94 @@ func CollectResources(m *sync.RWMutex, cycletime int32) {
 				NATs:                 natTotalDC,
 				NLBRules:             nlbTotalRulesDC,
 				ALBRules:             albTotalRulesDC,
-				ALBName:              albNames,
-				NLBName:              nlbNames,
-				ALBRuleName:          albRuleNames,
-				NLBRuleName:          nlbRuleNames,
-				TotalIPs:             totalIPs,
+				NLBDetails:           nlbDetails,
+				ALBDetails:           albDetails,
+				NATDetails:           natDetails,
 				TotalAPICallFailures: totalAPICallFailures,
+				ServerDetails:        serverDetails,
+				LANDetails:           lanDetails,
+				NICDetails:           nicDetails,
 			}
 
 		}
 
+		ipBlocks, err := fetchIPBlocks(ctx, apiClient)
+		if err != nil {
+			fmt.Printf("Error retrieving IP blocks: %v\n", err)
+		}
+		targetGroups, err := fetchTargetGroups(ctx, apiClient)
+		if err != nil {
+			fmt.Printf("Error retrieving target groups: %v\n", err)
+		}
+
+		// A run which timed out is incomplete, the previous snapshot is kept instead
+		if ctx.Err() != nil {
+			fmt.Printf("The datacenter scrape of account %s was cancelled, keeping the previous data: %v\n", account.Name, ctx.Err())
+			return
+		}
 		m.Lock()
-		IonosDatacenters = newIonosDatacenters
+		IonosDatacenters[account.Name] = newIonosDatacenters
+		if ipBlocks != nil {
+			IonosIPBlocks[account.Name] = processIPBlocks(ipBlocks, loadBalancerIps, natGatewayIps)
+		}
+		if targetGroups != nil {
+			var previousTargetGroups map[string]IonosTargetGroupResources
+			if !albsComplete {
+				previousTargetGroups = IonosTargetGroups[account.Name]
+			}
+			IonosTargetGroups[account.Name] = processTargetGroups(targetGroups, newIonosDatacenters, !albsComplete, previousTargetGroups)
+		}
 		m.Unlock()
 		CalculateDCTotals(m)
-		time.Sleep(time.Duration(cycletime) * time.Second)
-	}
+	})
 }
 
 func CalculateDCTotals(m *sync.RWMutex) {
-	var (
-		serverTotal      int32
-		ramTotal         int32
-		coresTotal       int32
-		datacentersTotal int32
-	)
+	newIonosTotals := make(map[string]IonosTotalResources)
 	m.RLock()
-	for _, dcResources := range IonosDatacenters {
-		serverTotal += dcResources.Servers
-		ramTotal += dcResources.Ram
-		coresTotal += dcResources.Cores
+	for account, datacenters := range IonosDatacenters {
+		var totals IonosTotalResources
+		for _, dcResources := range datacenters {
+			totals.Servers += dcResources.Servers
+			totals.Ram += dcResources.Ram
+			totals.Cores += dcResources.Cores
+		}
+		totals.DataCenters = int32(len(datacenters))
+		newIonosTotals[account] = totals
 	}
-	datacentersTotal = int32(len(IonosDatacenters))
 	m.RUnlock()
 	m.Lock()
-	ServerTotal = serverTotal
-	RamTotal = ramTotal
-	CoresTotal = coresTotal
-	DataCenters = datacentersTotal
+	IonosTotals = newIonosTotals
 	m.Unlock()
 }
 func PrintDCResources(m *sync.RWMutex) {
 	m.RLock()
 	defer m.RUnlock()
-	for dcName, dcResources := range IonosDatacenters {
-		fmt.Fprintf(os.Stdout, "%s:\n    - UUID: %s\n", dcName, dcResources.DCId)
-		fmt.Fprintf(os.Stdout, "    - Servers: %d\n", dcResources.Servers)
-		fmt.Fprintf(os.Stdout, "%s:\n    - Cores: %d\n", dcName, dcResources.Cores)
-		fmt.Fprintf(os.Stdout, "    - Ram: %d GB\n", dcResources.Ram/1024)
+	for account, datacenters := range IonosDatacenters {
+		for dcName, dcResources := range datacenters {
+			fmt.Fprintf(os.Stdout, "%s/%s:\n    - UUID: %s\n", account, dcName, dcResources.DCId)
+			fmt.Fprintf(os.Stdout, "    - Servers: %d\n", dcResources.Servers)
+			fmt.Fprintf(os.Stdout, "%s/%s:\n    - Cores: %d\n", account, dcName, dcResources.Cores)
+			fmt.Fprintf(os.Stdout, "    - Ram: %d GB\n", dcResources.Ram/1024)
+		}
 	}
 }
 func PrintDCTotals(m *sync.RWMutex) {
 	m.RLock()
 	defer m.RUnlock()
-	log.Printf("Total - Datacenters: %d\n", DataCenters)
-	log.Printf("Total - Servers: %d\n", ServerTotal)
-	log.Printf("Total - Cores: %d\n", CoresTotal)
-	log.Printf("Total - Ram: %d GB\n", RamTotal/1024)
+	for account, totals := range IonosTotals {
+		log.Printf("Total %s - Datacenters: %d\n", account, totals.DataCenters)
+		log.Printf("Total %s - Servers: %d\n", account, totals.Servers)
+		log.Printf("Total %s - Cores: %d\n", account, totals.Cores)
+		log.Printf("Total %s - Ram: %d GB\n", account, totals.Ram/1024)
+	}
 }
 
 /*
 Retrieves a list of NAT Gateways which are associated with specific datanceter using the ionoscloud API Client
 
 Parameters:
+ctx: context of the collection run, cancelled on timeout
 apiClient: An instance of APIClient for making API Requests
 datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
 
@@ -196,9 +326,9 @@ Returns:
 - *ionoscloud.NatGateways: A pointer to ionoscloud.NatGateways which has NAT List or an error if it fails
 If successful, it returns a pointer to the fetched NATs, otherwise it returns nil and an error message.
 */
-func fetchNATGateways(apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.NatGateways, error) {
+func fetchNATGateways(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.NatGateways, error) {
 	datacenterId := *datacenter.Id
-	natList, resp, err := apiClient.NATGatewaysApi.DatacentersNatgatewaysGet(context.Background(), datacenterId).Depth(2).Execute()
+	natList, resp, err := apiClient.NATGatewaysApi.DatacentersNatgatewaysGet(ctx, datacenterId).Depth(2).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling NATGateways API: %v\n", err)
 		if resp != nil {
@@ -219,6 +349,7 @@ func fetchNATGateways(apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Da
 Retrieves a list of Network Load Balancers (NLB) which are associated with specific datanceter using the ionoscloud API Client
 
 Parameters:
+ctx: context of the collection run, cancelled on timeout
 apiClient: An instance of APIClient for making API Requests
 datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
 
@@ -226,9 +357,9 @@ Returns:
 - *ionoscloud.NetworkLoadBalancers: A pointer to ionoscloud.ApplicationLoadbalancers which has ALB List or an error if it fails
 If successful, it returns a pointer to the fetched ALBs, otherwise it returns nil and an error message.
 */
-func fetchNetworkLoadBalancers(apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.NetworkLoadBalancers, error) {
+func fetchNetworkLoadBalancers(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.NetworkLoadBalancers, error) {
 	datacenterId := *datacenter.Id
-	nlbList, resp, err := apiClient.NetworkLoadBalancersApi.DatacentersNetworkloadbalancersGet(context.Background(), datacenterId).Depth(2).Execute()
+	nlbList, resp, err := apiClient.NetworkLoadBalancersApi.DatacentersNetworkloadbalancersGet(ctx, datacenterId).Depth(2).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling NetworkLoadbalancers API: %v\n", err)
 		if resp != nil {
@@ -250,6 +381,7 @@ func fetchNetworkLoadBalancers(apiClient *ionoscloud.APIClient, datacenter *iono
 retrievers a list of IP Blocks from ionoscloud API
 
 Parameters:
+  - ctx: context of the collection run, cancelled on timeout
   - apiClient: An instance of ionoscloud.APIClient
 
 Returns:
@@ -258,8 +390,8 @@ Returns:
 in the resource.
 - error: An error if there was an issue making the API call or if no IP blocks were found.
 */
-func fetchIPBlocks(apiClient *ionoscloud.APIClient) (*ionoscloud.IpBlocks, error) {
-	ipBlocks, resp, err := apiClient.IPBlocksApi.IpblocksGet(context.Background()).Depth(2).Execute()
+func fetchIPBlocks(ctx context.Context, apiClient *ionoscloud.APIClient) (*ionoscloud.IpBlocks, error) {
+	ipBlocks, resp, err := apiClient.IPBlocksApi.IpblocksGet(ctx).Depth(2).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling IPBlocks API: %v\n", err)
 		if resp != nil {
@@ -281,6 +413,7 @@ func fetchIPBlocks(apiClient *ionoscloud.APIClient) (*ionoscloud.IpBlocks, error
 Retrieves a list of Application Load Balancers (ALB) which are associated with specific datanceter using the ionoscloud API Client
 
 Parameters:
+ctx: context of the collection run, cancelled on timeout
 apiClient: An instance of APIClient for making API Requests
 datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
 
@@ -288,9 +421,9 @@ Returns:
 - *ionoscloud.ApplicationLoadBalancers: A pointer to ionoscloud.ApplicationLoadbalancers which has ALB List or an error if it fails
 If successful, it returns a pointer to the fetched ALBs, otherwise it returns nil and an error message.
 */
-func fetchApplicationLoadbalancers(apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.ApplicationLoadBalancers, error) {
+func fetchApplicationLoadbalancers(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.ApplicationLoadBalancers, error) {
 	datacenterId := *datacenter.Id
-	albList, resp, err := apiClient.ApplicationLoadBalancersApi.DatacentersApplicationloadbalancersGet(context.Background(), datacenterId).Depth(2).Execute()
+	albList, resp, err := apiClient.ApplicationLoadBalancersApi.DatacentersApplicationloadbalancersGet(ctx, datacenterId).Depth(2).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling ApplicationLoadBalancers API: %v\n", err)
 		if resp != nil {
@@ -309,94 +442,347 @@ func fetchApplicationLoadbalancers(apiClient *ionoscloud.APIClient, datacenter *
 }
 
 /*
-Calculates total number of IP addresses from a list of IP Blocks
+Retrieves the list of all ALB target groups of the account using the ionoscloud API Client
 
 Parameters:
-- ipBlocks: A pointer to ionoscloud.IpBlocks containing a list of IP blocks to process.
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
 
 Returns:
-- The total number of Ip addresses summed from all IP Blocks
+  - *ionoscloud.TargetGroups: A pointer to ionoscloud.TargetGroups or an error if it fails
 */
-func processIPBlocks(ipBlocks *ionoscloud.IpBlocks) int32 {
+func fetchTargetGroups(ctx context.Context, apiClient *ionoscloud.APIClient) (*ionoscloud.TargetGroups, error) {
+	targetGroups, resp, err := apiClient.TargetGroupsApi.TargetgroupsGet(ctx).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling TargetGroups API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if targetGroups.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+
+	return &targetGroups, nil
+}
+
+/*
+Extracts algorithm, protocol, targets and health check settings of the target groups and
+counts how many ALB forwarding rules reference each of them
+
+Parameters:
+  - targetGroups: A pointer to ionoscloud.TargetGroups containing the target groups to process
+  - datacenters: the already processed datacenters, whose ALB forwarding rules are checked for references
+  - incomplete: the ALBs of at least one datacenter are missing, the references are then taken from previous
+  - previous: the target groups of the previous run, only used if incomplete is set
+
+Returns:
+  - map[string]IonosTargetGroupResources: target group details keyed by the target group UUID
+*/
+func processTargetGroups(targetGroups *ionoscloud.TargetGroups, datacenters map[string]IonosDCResources, incomplete bool, previous map[string]IonosTargetGroupResources) map[string]IonosTargetGroupResources {
+	references := make(map[string]int32)
+	for _, dcResources := range datacenters {
+		for _, alb := range dcResources.ALBDetails {
+			for _, rule := range alb.Rules {
+				for _, targetGroupId := range rule.TargetGroups {
+					references[targetGroupId]++
+				}
+			}
+		}
+	}
 
-	var totalIPs int32
+	targetGroupDetails := make(map[string]IonosTargetGroupResources)
+	for _, targetGroup := range *targetGroups.Items {
+		if targetGroup.Id == nil || targetGroup.Properties == nil {
+			fmt.Println("TargetGroup Id or TargetGroup Properties are nil")
+			continue
+		}
+		properties := targetGroup.Properties
+		targetGroupDetail := IonosTargetGroupResources{
+			Name:       ionoscloud.ToValueDefault(properties.Name),
+			Algorithm:  ionoscloud.ToValueDefault(properties.Algorithm),
+			Protocol:   ionoscloud.ToValueDefault(properties.Protocol),
+			References: references[*targetGroup.Id],
+		}
+		if incomplete {
+			// Counting the known ALBs only would report target groups of the missing ones as unreferenced
+			previousTargetGroup, ok := previous[*targetGroup.Id]
+			targetGroupDetail.References = previousTargetGroup.References
+			targetGroupDetail.ReferencesUnknown = !ok || previousTargetGroup.ReferencesUnknown
+		}
+		for _, target := range ionoscloud.ToValueDefault(properties.Targets) {
+			targetGroupDetail.Targets++
+			if ionoscloud.ToValueDefault(target.HealthCheckEnabled) {
+				targetGroupDetail.HealthCheckEnabledTargets++
+			}
+		}
+		if properties.HealthCheck != nil {
+			targetGroupDetail.CheckInterval = ionoscloud.ToValueDefault(properties.HealthCheck.CheckInterval)
+			targetGroupDetail.CheckTimeout = ionoscloud.ToValueDefault(properties.HealthCheck.CheckTimeout)
+			targetGroupDetail.Retries = ionoscloud.ToValueDefault(properties.HealthCheck.Retries)
+		}
+		if properties.HttpHealthCheck != nil {
+			targetGroupDetail.HttpHealthCheckPath = ionoscloud.ToValueDefault(properties.HttpHealthCheck.Path)
+			targetGroupDetail.HttpHealthCheckMethod = ionoscloud.ToValueDefault(properties.HttpHealthCheck.Method)
+			targetGroupDetail.HttpHealthCheckMatchType = ionoscloud.ToValueDefault(properties.HttpHealthCheck.MatchType)
+			targetGroupDetail.HttpHealthCheckResponse = ionoscloud.ToValueDefault(properties.HttpHealthCheck.Response)
+		}
+		targetGroupDetails[*targetGroup.Id] = targetGroupDetail
+	}
+	return targetGroupDetails
+}
+
+// Consumer types of IP block IPs, an IP matching several types is counted for the first one
+var ipConsumerTypes = []string{"natgateway", "loadbalancer", "k8s", "nic", "other"}
+
+/*
+Extracts size, location and usage of every IP block
+
+Parameters:
+  - ipBlocks: A pointer to ionoscloud.IpBlocks containing a list of IP blocks to process.
+  - loadBalancerIps: listener IPs of all NLBs and ALBs of the account
+  - natGatewayIps: public IPs of all NAT gateways of the account
+
+Returns:
+  - map[string]IonosIPBlockResources: IP block details keyed by the IP block UUID
+
+The IP consumers of a block are counted per consumer type. IPs of loadbalancers and NAT gateways
+are recognised by their address, all other consumers by the resource they reference. An IP listed
+by several consumers, e.g. a NIC and a failover entry, is counted once for the first type in
+ipConsumerTypes it matches.
+*/
+func processIPBlocks(ipBlocks *ionoscloud.IpBlocks, loadBalancerIps map[string]bool, natGatewayIps map[string]bool) map[string]IonosIPBlockResources {
+	ipBlockDetails := make(map[string]IonosIPBlockResources)
 
 	for _, ips := range *ipBlocks.Items {
-		if ips.Properties != nil && ips.Properties.Size != nil {
-			totalIPs += *ips.Properties.Size
-		} else {
-			fmt.Println("Ip Properties or Ip Properties Size is nil")
+		if ips.Id == nil || ips.Properties == nil || ips.Properties.Size == nil {
+			fmt.Println("Ip Id, Ip Properties or Ip Properties Size is nil")
+			continue
+		}
+		ipBlockDetail := IonosIPBlockResources{
+			Name:     ionoscloud.ToValueDefault(ips.Properties.Name),
+			Location: ionoscloud.ToValueDefault(ips.Properties.Location),
+			Size:     *ips.Properties.Size,
+			UsedIps:  make(map[string]int32),
+		}
+		consumerTypes := make(map[string]int) // Index into ipConsumerTypes, keyed by the IP
+		for _, consumer := range ionoscloud.ToValueDefault(ips.Properties.IpConsumers) {
+			ip := ionoscloud.ToValueDefault(consumer.Ip)
+			var consumerType int
+			switch {
+			case natGatewayIps[ip]:
+				consumerType = 0
+			case loadBalancerIps[ip]:
+				consumerType = 1
+			case consumer.K8sNodePoolUuid != nil || consumer.K8sClusterUuid != nil:
+				consumerType = 2
+			case consumer.NicId != nil:
+				consumerType = 3
+			default:
+				consumerType = 4
+			}
+			if previousType, ok := consumerTypes[ip]; !ok || consumerType < previousType {
+				consumerTypes[ip] = consumerType
+			}
+		}
+		for _, consumerType := range consumerTypes {
+			ipBlockDetail.UsedIps[ipConsumerTypes[consumerType]]++
+		}
+		ipBlockDetails[*ips.Id] = ipBlockDetail
+	}
+	return ipBlockDetails
+}
+
+/*
+Extracts the per server details from a list of servers of a datacenter
+
+Parameters:
+  - servers: A pointer to ionoscloud.Servers containing the servers of one datacenter
+
+Returns:
+  - []IonosServerResources: name, UUID, cores, RAM, CPU family, type, availability zone and VM state of every server
+
+Servers without properties are skipped during processing.
+*/
+func processServers(servers *ionoscloud.Servers) []IonosServerResources {
+	if servers.Items == nil {
+		return nil
+	}
+	serverDetails := make([]IonosServerResources, 0, len(*servers.Items))
+
+	for _, server := range *servers.Items {
+		if server.Properties == nil {
+			fmt.Println("Server Properties are nil")
+			continue
 		}
+		serverDetails = append(serverDetails, IonosServerResources{
+			Id:               ionoscloud.ToValueDefault(server.Id),
+			Name:             ionoscloud.ToValueDefault(server.Properties.Name),
+			Cores:            ionoscloud.ToValueDefault(server.Properties.Cores),
+			Ram:              ionoscloud.ToValueDefault(server.Properties.Ram),
+			CpuFamily:        ionoscloud.ToValueDefault(server.Properties.CpuFamily),
+			Type:             ionoscloud.ToValueDefault(server.Properties.Type),
+			AvailabilityZone: ionoscloud.ToValueDefault(server.Properties.AvailabilityZone),
+			VmState:          ionoscloud.ToValueDefault(server.Properties.VmState),
+		})
 	}
-	return totalIPs
+	return serverDetails
 }
 
 /*
-process a list of Network Load Balancers to extract information about NLB names
-and total forwarding rules across all NLBs.
+process a list of Network Load Balancers to extract the details of every NLB
+and its forwarding rules, and the total forwarding rules across all NLBs.
 
 Parameter:
   - a pointer to the NetworkLoadbalaners containig a list of NLBs to process
 
 Returns:
-  - string: names of loadbalancers
+  - []IonosLoadBalancerResources: ID, name, listener IPs, state and forwarding rules of every NLB
   - int32: total number of forwarding rules
 
 If any NLB or its associated forwarding rules are nil, they are skipped during processing.
 */
-func processNetworkLoadBalancers(nlbList *ionoscloud.NetworkLoadBalancers) (string, int32) {
+func processNetworkLoadBalancers(nlbList *ionoscloud.NetworkLoadBalancers) ([]IonosLoadBalancerResources, int32) {
 	var (
-		nlbNames        string
+		nlbDetails      []IonosLoadBalancerResources
 		nlbTotalRulesDC int32
 	)
 
 	for _, nlb := range *nlbList.Items {
-		if nlb.Properties != nil && nlb.Properties.Name != nil {
-			nlbNames = *nlb.Properties.Name
-		}
-		nlbForwardingRules := nlb.Entities.Forwardingrules
-		if nlbForwardingRules != nil && nlbForwardingRules.Items != nil {
-			nlbTotalRulesDC = int32(len(*nlbForwardingRules.Items))
-			for _, rule := range *nlbForwardingRules.Items {
-				if rule.Properties != nil && rule.Properties.Name != nil {
-					nlbNames = *rule.Properties.Name
+		if nlb.Id == nil || nlb.Properties == nil {
+			continue
+		}
+		nlbDetail := IonosLoadBalancerResources{
+			Id:          *nlb.Id,
+			Name:        ionoscloud.ToValueDefault(nlb.Properties.Name),
+			ListenerIps: strings.Join(ionoscloud.ToValueDefault(nlb.Properties.Ips), ","),
+		}
+		if nlb.Metadata != nil {
+			nlbDetail.State = ionoscloud.ToValueDefault(nlb.Metadata.State)
+		}
+		if nlb.Entities != nil && nlb.Entities.Forwardingrules != nil && nlb.Entities.Forwardingrules.Items != nil {
+			for _, rule := range *nlb.Entities.Forwardingrules.Items {
+				if rule.Id == nil || rule.Properties == nil {
+					continue
 				}
+				nlbDetail.Rules = append(nlbDetail.Rules, IonosForwardingRuleResources{
+					Id:           *rule.Id,
+					Name:         ionoscloud.ToValueDefault(rule.Properties.Name),
+					Protocol:     ionoscloud.ToValueDefault(rule.Properties.Protocol),
+					ListenerPort: ionoscloud.ToValueDefault(rule.Properties.ListenerPort),
+					Targets:      int32(len(ionoscloud.ToValueDefault(rule.Properties.Targets))),
+				})
 			}
 		}
+		nlbTotalRulesDC += int32(len(nlbDetail.Rules))
+		nlbDetails = append(nlbDetails, nlbDetail)
 	}
-	return nlbNames, nlbTotalRulesDC
+	return nlbDetails, nlbTotalRulesDC
 }
 
 /*
-process a list of Application Load Balancers ALBs to extract information about ALB names and
-total forwarding rules across al ALBs
+process a list of Application Load Balancers ALBs to extract the details of every ALB
+and its forwarding rules, and the total forwarding rules across all ALBs
 
 Parameters:
   - a pointer to ApplicationLoadBalancers containing a list of ALBs to process
 
 Returns:
-  - string: names of application loadbalancers
+  - []IonosLoadBalancerResources: ID, name, listener IPs, state and forwarding rules of every ALB
   - int32: total number of forwarding rules
+
+If any ALB or its associated forwarding rules are nil, they are skipped during processing.
 */
-func processApplicationLoadBalancers(albList *ionoscloud.ApplicationLoadBalancers) (string, int32) {
+func processApplicationLoadBalancers(albList *ionoscloud.ApplicationLoadBalancers) ([]IonosLoadBalancerResources, int32) {
 	var (
-		albNames        string
+		albDetails      []IonosLoadBalancerResources
 		albTotalRulesDC int32
 	)
 
 	for _, alb := range *albList.Items {
-		if alb.Properties != nil && alb.Properties.Name != nil {
-			albNames = *alb.Properties.Name
-		}
-		albForwardingRules := alb.Entities.Forwardingrules
-		if albForwardingRules != nil && albForwardingRules.Items != nil {
-			albTotalRulesDC = int32(len(*albForwardingRules.Items))
-			for _, rule := range *albForwardingRules.Items {
-				if rule.Properties != nil && rule.Properties.Name != nil {
-					albNames = *rule.Properties.Name
+		if alb.Id == nil || alb.Properties == nil {
+			continue
+		}
+		albDetail := IonosLoadBalancerResources{
+			Id:          *alb.Id,
+			Name:        ionoscloud.ToValueDefault(alb.Properties.Name),
+			ListenerIps: strings.Join(ionoscloud.ToValueDefault(alb.Properties.Ips), ","),
+		}
+		if alb.Metadata != nil {
+			albDetail.State = ionoscloud.ToValueDefault(alb.Metadata.State)
+		}
+		if alb.Entities != nil && alb.Entities.Forwardingrules != nil && alb.Entities.Forwardingrules.Items != nil {
+			for _, rule := range *alb.Entities.Forwardingrules.Items {
+				if rule.Id == nil || rule.Properties == nil {
+					continue
+				}
+				ruleDetail := IonosForwardingRuleResources{
+					Id:           *rule.Id,
+					Name:         ionoscloud.ToValueDefault(rule.Properties.Name),
+					Protocol:     ionoscloud.ToValueDefault(rule.Properties.Protocol),
+					ListenerPort: ionoscloud.ToValueDefault(rule.Properties.ListenerPort),
+				}
+				seenTargetGroups := make(map[string]bool)
+				for _, httpRule := range ionoscloud.ToValueDefault(rule.Properties.HttpRules) {
+					if httpRule.TargetGroup != nil && !seenTargetGroups[*httpRule.TargetGroup] {
+						seenTargetGroups[*httpRule.TargetGroup] = true
+						ruleDetail.TargetGroups = append(ruleDetail.TargetGroups, *httpRule.TargetGroup)
+					}
+				}
+				ruleDetail.Targets = int32(len(ruleDetail.TargetGroups))
+				albDetail.Rules = append(albDetail.Rules, ruleDetail)
+			}
+		}
+		albTotalRulesDC += int32(len(albDetail.Rules))
+		albDetails = append(albDetails, albDetail)
+	}
+	return albDetails, albTotalRulesDC
+}
+
+/*
+process a list of NAT Gateways to extract public IPs, SNAT rules, connected LANs and state of every NAT Gateway
+
+Parameters:
+  - a pointer to NatGateways containing a list of NAT Gateways to process
+
+Returns:
+  - []IonosNATGatewayResources: the details of every NAT Gateway
+
+If any NAT Gateway or its associated rules are nil, they are skipped during processing.
+*/
+func processNATGateways(natList *ionoscloud.NatGateways) []IonosNATGatewayResources {
+	var natDetails []IonosNATGatewayResources
+
+	for _, nat := range *natList.Items {
+		if nat.Id == nil || nat.Properties == nil {
+			continue
+		}
+		natDetail := IonosNATGatewayResources{
+			Id:        *nat.Id,
+			Name:      ionoscloud.ToValueDefault(nat.Properties.Name),
+			PublicIps: ionoscloud.ToValueDefault(nat.Properties.PublicIps),
+		}
+		if nat.Metadata != nil {
+			natDetail.State = ionoscloud.ToValueDefault(nat.Metadata.State)
+		}
+		for _, lan := range ionoscloud.ToValueDefault(nat.Properties.Lans) {
+			natDetail.Lans = append(natDetail.Lans, IonosNATGatewayLanResources{
+				Id:         ionoscloud.ToValueDefault(lan.Id),
+				GatewayIps: strings.Join(ionoscloud.ToValueDefault(lan.GatewayIps), ","),
+			})
+		}
+		if nat.Entities != nil && nat.Entities.Rules != nil && nat.Entities.Rules.Items != nil {
+			for _, rule := range *nat.Entities.Rules.Items {
+				if rule.Properties != nil && ionoscloud.ToValueDefault(rule.Properties.Type) == ionoscloud.SNAT {
+					natDetail.SNATRules++
 				}
 			}
 		}
+		natDetails = append(natDetails, natDetail)
 	}
-	return albNames, albTotalRulesDC
+	return natDetails
 }
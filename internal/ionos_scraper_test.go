@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"testing"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+func TestProcessIPBlocks(t *testing.T) {
+	nicConsumer := func(ip string, nicId string) ionoscloud.IpConsumer {
+		return ionoscloud.IpConsumer{Ip: ionoscloud.PtrString(ip), NicId: ionoscloud.PtrString(nicId)}
+	}
+	tests := []struct {
+		name      string
+		size      int32
+		consumers []ionoscloud.IpConsumer
+		want      map[string]int32
+	}{
+		{
+			name:      "distinct ips",
+			size:      4,
+			consumers: []ionoscloud.IpConsumer{nicConsumer("192.0.2.1", "nic-1"), nicConsumer("192.0.2.2", "nic-2"), {Ip: ionoscloud.PtrString("192.0.2.3")}},
+			want:      map[string]int32{"nic": 2, "loadbalancer": 1},
+		},
+		{
+			name:      "failover ip on two nics",
+			size:      2,
+			consumers: []ionoscloud.IpConsumer{nicConsumer("192.0.2.1", "nic-1"), nicConsumer("192.0.2.1", "nic-2"), nicConsumer("192.0.2.2", "nic-3")},
+			want:      map[string]int32{"nic": 2},
+		},
+		{
+			name: "ip listed by a nic and a kubernetes node pool",
+			size: 1,
+			consumers: []ionoscloud.IpConsumer{
+				nicConsumer("192.0.2.1", "nic-1"),
+				{Ip: ionoscloud.PtrString("192.0.2.1"), K8sNodePoolUuid: ionoscloud.PtrString("nodepool-1")},
+			},
+			want: map[string]int32{"k8s": 1},
+		},
+		{
+			name:      "nat gateway ip listed twice",
+			size:      1,
+			consumers: []ionoscloud.IpConsumer{{Ip: ionoscloud.PtrString("192.0.2.4")}, nicConsumer("192.0.2.4", "nic-1")},
+			want:      map[string]int32{"natgateway": 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			consumers := test.consumers
+			ipBlocks := &ionoscloud.IpBlocks{Items: &[]ionoscloud.IpBlock{{
+				Id: ionoscloud.PtrString("ipblock-1"),
+				Properties: &ionoscloud.IpBlockProperties{
+					Size:        ionoscloud.PtrInt32(test.size),
+					IpConsumers: &consumers,
+				},
+			}}}
+			got := processIPBlocks(ipBlocks, map[string]bool{"192.0.2.3": true}, map[string]bool{"192.0.2.4": true})["ipblock-1"]
+
+			var used int32
+			for consumer, want := range test.want {
+				if got.UsedIps[consumer] != want {
+					t.Errorf("%s: expected %d used IPs, got %d", consumer, want, got.UsedIps[consumer])
+				}
+				used += want
+			}
+			if len(got.UsedIps) != len(test.want) {
+				t.Errorf("expected consumers %v, got %v", test.want, got.UsedIps)
+			}
+			if used > got.Size {
+				t.Errorf("expected at most %d used IPs, got %d", got.Size, used)
+			}
+		})
+	}
+}
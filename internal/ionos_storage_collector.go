@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type storageCollector struct {
+	mutex                   *sync.RWMutex
+	volumeSizeMetric        *prometheus.GaugeVec
+	volumeAttachedMetric    *prometheus.GaugeVec
+	dcVolumeSizeMetric      *prometheus.GaugeVec
+	snapshotSizeMetric      *prometheus.GaugeVec
+	snapshotAgeMetric       *prometheus.GaugeVec
+	totalSnapshotSizeMetric *prometheus.GaugeVec
+}
+
+func NewStorageCollector(m *sync.RWMutex) *storageCollector {
+	return &storageCollector{
+		mutex: m,
+		volumeSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_volume_size_gb",
+			Help: "Shows the size of a block storage volume in an IONOS datacenter in GB",
+		}, []string{"account", "datacenter", "volume", "volume_id", "type", "bus", "server", "server_id"}),
+		volumeAttachedMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_volume_attached",
+			Help: "Shows whether a block storage volume is attached to a server (1) or orphaned (0)",
+		}, []string{"account", "datacenter", "volume", "volume_id", "type"}),
+		dcVolumeSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_dc_volume_size_gb",
+			Help: "Shows the total size of all block storage volumes of a disk type in an IONOS datacenter in GB",
+		}, []string{"account", "datacenter", "type"}),
+		snapshotSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_snapshot_size_gb",
+			Help: "Shows the size of a snapshot in GB",
+		}, []string{"account", "snapshot", "snapshot_id", "location", "licence_type"}),
+		snapshotAgeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_snapshot_age_seconds",
+			Help: "Shows the time since a snapshot was created in seconds",
+		}, []string{"account", "snapshot", "snapshot_id", "location", "licence_type"}),
+		totalSnapshotSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_total_snapshot_size_gb",
+			Help: "Shows the total size of all snapshots of an IONOS account in GB",
+		}, []string{"account"}),
+	}
+}
+
+func (collector *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.volumeSizeMetric.Describe(ch)
+	collector.volumeAttachedMetric.Describe(ch)
+	collector.dcVolumeSizeMetric.Describe(ch)
+	collector.snapshotSizeMetric.Describe(ch)
+	collector.snapshotAgeMetric.Describe(ch)
+	collector.totalSnapshotSizeMetric.Describe(ch)
+}
+
+func (collector *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+
+	// Reset metrics in case a volume or snapshot was removed
+	collector.volumeSizeMetric.Reset()
+	collector.volumeAttachedMetric.Reset()
+	collector.dcVolumeSizeMetric.Reset()
+	collector.snapshotSizeMetric.Reset()
+	collector.snapshotAgeMetric.Reset()
+	collector.totalSnapshotSizeMetric.Reset()
+
+	for account, datacenters := range IonosVolumes {
+		for dcName, volumes := range datacenters {
+			sizePerType := make(map[string]float64)
+			for _, volume := range volumes {
+				attached := 0.0
+				if volume.ServerId != "" {
+					attached = 1
+				}
+				collector.volumeSizeMetric.WithLabelValues(account, dcName, volume.Name, volume.Id, volume.Type, volume.Bus, volume.ServerName, volume.ServerId).Set(float64(volume.Size))
+				collector.volumeAttachedMetric.WithLabelValues(account, dcName, volume.Name, volume.Id, volume.Type).Set(attached)
+				sizePerType[volume.Type] += float64(volume.Size)
+			}
+			for volumeType, size := range sizePerType {
+				collector.dcVolumeSizeMetric.WithLabelValues(account, dcName, volumeType).Set(size)
+			}
+		}
+	}
+
+	for account, snapshots := range IonosSnapshots {
+		var totalSnapshotSize float64
+		for _, snapshot := range snapshots {
+			collector.snapshotSizeMetric.WithLabelValues(account, snapshot.Name, snapshot.Id, snapshot.Location, snapshot.LicenceType).Set(float64(snapshot.Size))
+			if !snapshot.CreatedDate.IsZero() {
+				collector.snapshotAgeMetric.WithLabelValues(account, snapshot.Name, snapshot.Id, snapshot.Location, snapshot.LicenceType).Set(time.Since(snapshot.CreatedDate).Seconds())
+			}
+			totalSnapshotSize += float64(snapshot.Size)
+		}
+		collector.totalSnapshotSizeMetric.WithLabelValues(account).Set(totalSnapshotSize)
+	}
+
+	collector.volumeSizeMetric.Collect(ch)
+	collector.volumeAttachedMetric.Collect(ch)
+	collector.dcVolumeSizeMetric.Collect(ch)
+	collector.snapshotSizeMetric.Collect(ch)
+	collector.snapshotAgeMetric.Collect(ch)
+	collector.totalSnapshotSizeMetric.Collect(ch)
+}
@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	ionoscloud "github.com/ionos-cloud/sdk-go/v6"
+)
+
+var (
+	IonosVolumes   = make(map[string]map[string][]IonosVolumeResources) //Key is the name of the account, then the name of the datacenter
+	IonosSnapshots = make(map[string][]IonosSnapshotResources)          //Key is the name of the account
+)
+
+type IonosVolumeResources struct {
+	Id         string  // UUID of the volume
+	Name       string  // Name of the volume
+	Size       float32 // Size of the volume in GB
+	Type       string  // Disk type, HDD, SSD Standard or SSD Premium
+	Bus        string  // Bus type, VIRTIO or IDE
+	ServerId   string  // UUID of the server the volume is attached to, empty if the volume is not attached
+	ServerName string  // Name of the server the volume is attached to, empty if the volume is not attached
+}
+
+type IonosSnapshotResources struct {
+	Id          string    // UUID of the snapshot
+	Name        string    // Name of the snapshot
+	Size        float32   // Size of the snapshot in GB
+	Location    string    // Location the snapshot is stored in
+	LicenceType string    // OS type of the snapshot
+	CreatedDate time.Time // Creation time of the snapshot, used to calculate its age
+}
+
+func StorageCollectResources(m *sync.RWMutex, account AccountConfig, config CollectorConfig) {
+	cfgENV := account.IonosConfiguration()
+	apiClient := ionoscloud.NewAPIClient(cfgENV)
+
+	config.Run("storage", account, func(ctx context.Context) {
+		datacenters, resp, err := apiClient.DataCentersApi.DatacentersGet(ctx).Depth(depth).Execute()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error when calling `DataCentersApi.DatacentersGet``: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+			return
+		}
+		newIonosVolumes := make(map[string][]IonosVolumeResources)
+		for _, datacenter := range *datacenters.Items {
+			volumes, err := fetchVolumes(ctx, apiClient, &datacenter)
+			if err != nil {
+				fmt.Printf("Error retrieving volumes for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				continue
+			}
+			servers, err := fetchServersWithVolumes(ctx, apiClient, &datacenter)
+			if err != nil {
+				fmt.Printf("Error retrieving servers for datacenter %s: %v\n", *datacenter.Properties.Name, err)
+				continue
+			}
+			newIonosVolumes[*datacenter.Properties.Name] = processVolumes(volumes, servers)
+		}
+
+		snapshots, err := fetchSnapshots(ctx, apiClient)
+		if err != nil {
+			fmt.Printf("Error retrieving snapshots: %v\n", err)
+		}
+
+		// A run which timed out is incomplete, the previous snapshot is kept instead
+		if ctx.Err() != nil {
+			fmt.Printf("The storage scrape of account %s was cancelled, keeping the previous data: %v\n", account.Name, ctx.Err())
+			return
+		}
+		m.Lock()
+		IonosVolumes[account.Name] = newIonosVolumes
+		if snapshots != nil {
+			IonosSnapshots[account.Name] = processSnapshots(snapshots)
+		}
+		m.Unlock()
+	})
+}
+
+/*
+Retrieves a list of volumes which are associated with specific datacenter using the ionoscloud API Client
+
+Parameters:
+ctx: context of the collection run, cancelled on timeout
+apiClient: An instance of APIClient for making API Requests
+datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
+
+Returns:
+- *ionoscloud.Volumes: A pointer to ionoscloud.Volumes which has the volume list or an error if it fails
+*/
+func fetchVolumes(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.Volumes, error) {
+	datacenterId := *datacenter.Id
+	volumes, resp, err := apiClient.VolumesApi.DatacentersVolumesGet(ctx, datacenterId).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling Volumes API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if volumes.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &volumes, nil
+}
+
+/*
+Retrieves a list of servers of a specific datacenter including the references to their attached volumes
+
+Parameters:
+ctx: context of the collection run, cancelled on timeout
+apiClient: An instance of APIClient for making API Requests
+datacenter Pointer to an ionoscloud.Datacenter object representing the target datacenter.
+
+Returns:
+- *ionoscloud.Servers: A pointer to ionoscloud.Servers which has the server list or an error if it fails
+*/
+func fetchServersWithVolumes(ctx context.Context, apiClient *ionoscloud.APIClient, datacenter *ionoscloud.Datacenter) (*ionoscloud.Servers, error) {
+	datacenterId := *datacenter.Id
+	servers, resp, err := apiClient.ServersApi.DatacentersServersGet(ctx, datacenterId).Depth(2).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling Servers API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if servers.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &servers, nil
+}
+
+/*
+Retrieves the list of all snapshots of the account using the ionoscloud API Client
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - apiClient: An instance of ionoscloud.APIClient
+
+Returns:
+  - *ionoscloud.Snapshots: A pointer to ionoscloud.Snapshots or an error if it fails
+*/
+func fetchSnapshots(ctx context.Context, apiClient *ionoscloud.APIClient) (*ionoscloud.Snapshots, error) {
+	snapshots, resp, err := apiClient.SnapshotsApi.SnapshotsGet(ctx).Depth(1).Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when calling Snapshots API: %v\n", err)
+		if resp != nil {
+			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", resp)
+		} else {
+			fmt.Fprintf(os.Stderr, "No HTTP response received\n")
+		}
+		return nil, err
+	}
+
+	if snapshots.Items == nil {
+		return nil, fmt.Errorf("no items in resource")
+	}
+	return &snapshots, nil
+}
+
+/*
+Combines the volumes of a datacenter with the servers they are attached to
+
+Parameters:
+  - volumes: A pointer to ionoscloud.Volumes containing the volumes of one datacenter
+  - servers: A pointer to ionoscloud.Servers containing the servers of the same datacenter with their attached volumes
+
+Returns:
+  - []IonosVolumeResources: size, disk type, bus type and attached server of every volume
+
+Volumes which are not attached to any server have an empty ServerId and ServerName.
+*/
+func processVolumes(volumes *ionoscloud.Volumes, servers *ionoscloud.Servers) []IonosVolumeResources {
+	attachedServers := make(map[string]ionoscloud.Server)
+	for _, server := range *servers.Items {
+		if server.Entities == nil || server.Entities.Volumes == nil || server.Entities.Volumes.Items == nil {
+			continue
+		}
+		for _, volume := range *server.Entities.Volumes.Items {
+			if volume.Id != nil {
+				attachedServers[*volume.Id] = server
+			}
+		}
+	}
+
+	volumeDetails := make([]IonosVolumeResources, 0, len(*volumes.Items))
+	for _, volume := range *volumes.Items {
+		if volume.Id == nil || volume.Properties == nil {
+			fmt.Println("Volume Id or Volume Properties are nil")
+			continue
+		}
+		volumeDetail := IonosVolumeResources{
+			Id:   *volume.Id,
+			Name: ionoscloud.ToValueDefault(volume.Properties.Name),
+			Size: ionoscloud.ToValueDefault(volume.Properties.Size),
+			Type: ionoscloud.ToValueDefault(volume.Properties.Type),
+			Bus:  ionoscloud.ToValueDefault(volume.Properties.Bus),
+		}
+		if server, ok := attachedServers[*volume.Id]; ok {
+			volumeDetail.ServerId = ionoscloud.ToValueDefault(server.Id)
+			if server.Properties != nil {
+				volumeDetail.ServerName = ionoscloud.ToValueDefault(server.Properties.Name)
+			}
+		}
+		volumeDetails = append(volumeDetails, volumeDetail)
+	}
+	return volumeDetails
+}
+
+/*
+Extracts the details of all snapshots of the account
+
+Parameters:
+  - snapshots: A pointer to ionoscloud.Snapshots containing the snapshots to process
+
+Returns:
+  - []IonosSnapshotResources: size, location, licence type and creation time of every snapshot
+*/
+func processSnapshots(snapshots *ionoscloud.Snapshots) []IonosSnapshotResources {
+	snapshotDetails := make([]IonosSnapshotResources, 0, len(*snapshots.Items))
+	for _, snapshot := range *snapshots.Items {
+		if snapshot.Id == nil || snapshot.Properties == nil {
+			fmt.Println("Snapshot Id or Snapshot Properties are nil")
+			continue
+		}
+		snapshotDetail := IonosSnapshotResources{
+			Id:          *snapshot.Id,
+			Name:        ionoscloud.ToValueDefault(snapshot.Properties.Name),
+			Size:        ionoscloud.ToValueDefault(snapshot.Properties.Size),
+			Location:    ionoscloud.ToValueDefault(snapshot.Properties.Location),
+			LicenceType: ionoscloud.ToValueDefault(snapshot.Properties.LicenceType),
+		}
+		if snapshot.Metadata != nil && snapshot.Metadata.CreatedDate != nil {
+			snapshotDetail.CreatedDate = snapshot.Metadata.CreatedDate.Time
+		}
+		snapshotDetails = append(snapshotDetails, snapshotDetail)
+	}
+	return snapshotDetails
+}
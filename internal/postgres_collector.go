@@ -10,6 +10,8 @@ import (
 
 type postgresCollector struct {
 	mutex                               *sync.RWMutex
+	postgresClusterInfoMetric           *prometheus.GaugeVec
+	postgresDatabaseInfoMetric          *prometheus.GaugeVec
 	postgresTotalRamMetric              *prometheus.GaugeVec
 	postgresTotalCPUMetric              *prometheus.GaugeVec
 	postgresTotalStorageMetric          *prometheus.GaugeVec
@@ -25,50 +27,60 @@ type postgresCollector struct {
 func NewPostgresCollector(m *sync.RWMutex) *postgresCollector {
 	return &postgresCollector{
 		mutex: m,
+		postgresClusterInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_dbaas_postgres_cluster_info",
+			Help: "Shows the owner of a postgres cluster, the value is always 1",
+		}, []string{"account", "cluster", "owner"}),
+		postgresDatabaseInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ionos_dbaas_postgres_database_info",
+			Help: "Shows a database of a postgres cluster, the value is always 1",
+		}, []string{"account", "cluster", "db"}),
 		postgresTotalRamMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_total_ram_in_cluster",
 			Help: "Gives the total ammount of allocated RAM in cluster",
-		}, []string{"cluster", "owner", "db"}),
+		}, []string{"account", "cluster"}),
 		postgresTotalCPUMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_total_cpu_in_cluster",
 			Help: "Gives a total amount of CPU Cores in Cluster",
-		}, []string{"cluster", "owner", "db"}),
+		}, []string{"account", "cluster"}),
 		postgresTotalStorageMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_total_storage_in_cluster",
 			Help: "Gives a total amount of Storage in Cluster",
-		}, []string{"cluster", "owner", "db"}),
+		}, []string{"account", "cluster"}),
 		postgresTransactionRateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_transactions:rate2m",
 			Help: "Gives a Transaction Rate in postgres cluster in 2m",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresTotalStorageBytesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_total_storage_metric",
 			Help: "Gives a Total Storage Metric in Bytes",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresAvailableStorageBytesMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_available_storage_metric",
 			Help: "Gives a Available Storage Metric in Bytes",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresCpuRateMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgress_cpu_rate5m",
 			Help: "Gives a CPU Rate (Average Utilization) over the past 5 Minutes",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresDiskIOMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_disk_io_time_weighted_seconds_rate5m",
 			Help: "The rate of disk I/O time, in seconds, over a five-minute period.",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresLoadMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_load5",
 			Help: "Linux load average for the last 5 minutes.",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 		postgresTotalMemoryAvailableBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "ionos_dbaas_postgres_memory_available_bytes",
 			Help: "Available memory in bytes",
-		}, []string{"cluster"}),
+		}, []string{"account", "cluster"}),
 	}
 }
 
 func (collector *postgresCollector) Describe(ch chan<- *prometheus.Desc) {
+	collector.postgresClusterInfoMetric.Describe(ch)
+	collector.postgresDatabaseInfoMetric.Describe(ch)
 	collector.postgresTotalCPUMetric.Describe(ch)
 	collector.postgresTotalRamMetric.Describe(ch)
 	collector.postgresTotalStorageMetric.Describe(ch)
@@ -85,65 +97,70 @@ func (collector *postgresCollector) Collect(ch chan<- prometheus.Metric) {
 	collector.mutex.RLock()
 	defer collector.mutex.RUnlock()
 
-	metricsMutex.Lock()
+	collector.postgresClusterInfoMetric.Reset()
+	collector.postgresDatabaseInfoMetric.Reset()
 	collector.postgresTotalCPUMetric.Reset()
 	collector.postgresTotalRamMetric.Reset()
 	collector.postgresTotalStorageMetric.Reset()
-	metricsMutex.Unlock()
 
-	for postgresName, postgresResources := range IonosPostgresClusters {
+	for account, clusters := range IonosPostgresClusters {
+		for postgresName, postgresResources := range clusters {
 
-		for _, telemetry := range postgresResources.Telemetry {
-			for _, value := range telemetry.Values {
-				if len(value) != 2 {
-					fmt.Printf("Unexpected value length: %v\n", value)
-					continue
-				}
-				metricValue, ok := value[1].(float64)
-				if !ok {
-					strValue, ok := value[1].(string)
-					if !ok {
-						fmt.Printf("Unexpected type for metric %s value: %v\n", telemetry.Values, value[1])
+			for _, telemetry := range postgresResources.Telemetry {
+				for _, value := range telemetry.Values {
+					if len(value) != 2 {
+						fmt.Printf("Unexpected value length: %v\n", value)
 						continue
 					}
+					metricValue, ok := value[1].(float64)
+					if !ok {
+						strValue, ok := value[1].(string)
+						if !ok {
+							fmt.Printf("Unexpected type for metric %s value: %v\n", telemetry.Values, value[1])
+							continue
+						}
 
-					var err error
-					metricValue, err = strconv.ParseFloat(strValue, 64)
-					if err != nil {
-						fmt.Printf("Failed to parse metric value: %v\n", err)
+						var err error
+						metricValue, err = strconv.ParseFloat(strValue, 64)
+						if err != nil {
+							fmt.Printf("Failed to parse metric value: %v\n", err)
+							continue
+						}
+					}
+					switch telemetry.Metric["__name__"] {
+					case "ionos_dbaas_postgres_transactions:rate2m":
+						collector.postgresTransactionRateMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_storage_total_bytes":
+						collector.postgresTotalStorageBytesMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_storage_available_bytes":
+						collector.postgresAvailableStorageBytesMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_cpu_rate5m":
+						collector.postgresCpuRateMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_disk_io_time_weighted_seconds_rate5m":
+						collector.postgresDiskIOMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_load5":
+						collector.postgresLoadMetric.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					case "ionos_dbaas_postgres_memory_available_bytes":
+						collector.postgresTotalMemoryAvailableBytes.WithLabelValues(account, postgresName).Set(float64(metricValue))
+					default:
+						// fmt.Printf("Unrecognised metric: %s\n", telemetry.Metric["__name__"])
 						continue
 					}
 				}
-				switch telemetry.Metric["__name__"] {
-				case "ionos_dbaas_postgres_transactions:rate2m":
-					collector.postgresTransactionRateMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_storage_total_bytes":
-					collector.postgresTotalStorageBytesMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_storage_available_bytes":
-					collector.postgresAvailableStorageBytesMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_cpu_rate5m":
-					collector.postgresCpuRateMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_disk_io_time_weighted_seconds_rate5m":
-					collector.postgresDiskIOMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_load5":
-					collector.postgresLoadMetric.WithLabelValues(postgresName).Set(float64(metricValue))
-				case "ionos_dbaas_postgres_memory_available_bytes":
-					collector.postgresTotalMemoryAvailableBytes.WithLabelValues(postgresName).Set(float64(metricValue))
-				default:
-					// fmt.Printf("Unrecognised metric: %s\n", telemetry.Metric["__name__"])
-					continue
-				}
 			}
-		}
 
-		for _, dbName := range postgresResources.DatabaseNames {
+			collector.postgresClusterInfoMetric.WithLabelValues(account, postgresName, postgresResources.Owner).Set(1)
+			for _, dbName := range postgresResources.DatabaseNames {
+				collector.postgresDatabaseInfoMetric.WithLabelValues(account, postgresName, dbName).Set(1)
+			}
+			collector.postgresTotalCPUMetric.WithLabelValues(account, postgresName).Set(float64(postgresResources.CPU))
+			collector.postgresTotalRamMetric.WithLabelValues(account, postgresName).Set(float64(postgresResources.RAM))
+			collector.postgresTotalStorageMetric.WithLabelValues(account, postgresName).Set(float64(postgresResources.Storage))
 
-			collector.postgresTotalCPUMetric.WithLabelValues(postgresName, postgresResources.Owner, dbName).Set(float64(postgresResources.CPU))
-			collector.postgresTotalRamMetric.WithLabelValues(postgresName, postgresResources.Owner, dbName).Set(float64(postgresResources.RAM))
-			collector.postgresTotalStorageMetric.WithLabelValues(postgresName, postgresResources.Owner, dbName).Set(float64(postgresResources.Storage))
 		}
-
 	}
+	collector.postgresClusterInfoMetric.Collect(ch)
+	collector.postgresDatabaseInfoMetric.Collect(ch)
 	collector.postgresTotalCPUMetric.Collect(ch)
 	collector.postgresTotalRamMetric.Collect(ch)
 	collector.postgresTotalStorageMetric.Collect(ch)
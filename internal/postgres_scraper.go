@@ -39,25 +39,20 @@ var (
 	ClusterCoresTotal     int32 = 0
 	ClusterRamTotal       int32 = 0
 	ClusterTotal          int32 = 0
-	IonosPostgresClusters       = make(map[string]IonosPostgresResources)
+	IonosPostgresClusters       = make(map[string]map[string]IonosPostgresResources) //Key is the name of the account, then the name of the cluster
 )
 
-func PostgresCollectResources(m *sync.RWMutex, configPath string, cycletime int32) {
-	cfgENV := psql.NewConfigurationFromEnv()
+func PostgresCollectResources(m *sync.RWMutex, account AccountConfig, config CollectorConfig, metrics []MetricConfig) {
+	cfgENV := account.PostgresConfiguration()
 	apiClient := psql.NewAPIClient(cfgENV)
 
-	config, err := LoadConfig(configPath)
-	if err != nil {
-		fmt.Println("Failed to load config:", err)
-	}
-	for {
-		processCluster(apiClient, m, config.Metrics)
-		time.Sleep(time.Duration(cycletime) * time.Second)
-	}
+	config.Run("postgres", account, func(ctx context.Context) {
+		processCluster(ctx, apiClient, m, account, metrics)
+	})
 }
 
-func processCluster(apiClient *psql.APIClient, m *sync.RWMutex, metrics []MetricConfig) {
-	datacenters, err := fetchClusters(apiClient)
+func processCluster(ctx context.Context, apiClient *psql.APIClient, m *sync.RWMutex, account AccountConfig, metrics []MetricConfig) {
+	datacenters, err := fetchClusters(ctx, apiClient)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to fetch clusters: %v\n", err)
 	}
@@ -77,12 +72,12 @@ func processCluster(apiClient *psql.APIClient, m *sync.RWMutex, metrics []Metric
 			fmt.Fprintf(os.Stderr, "Cluster name is nil\n")
 			continue
 		}
-		databaseNames, err := fetchDatabases(apiClient, *clusters.Id)
+		databaseNames, err := fetchDatabases(ctx, apiClient, *clusters.Id)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to fetch databases for cluster %s: %v\n", *clusters.Properties.DisplayName, err)
 			continue
 		}
-		databaseOwner, err := fetchOwner(apiClient, *clusters.Id)
+		databaseOwner, err := fetchOwner(ctx, apiClient, *clusters.Id)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to fetch owner for database %s: %v\n", *clusters.Properties.DisplayName, err)
 			continue
@@ -91,7 +86,7 @@ func processCluster(apiClient *psql.APIClient, m *sync.RWMutex, metrics []Metric
 		telemetryData := make([]TelemetryMetric, 0)
 
 		for _, metricConfig := range metrics {
-			telemetryResp, err := fetchTelemetryMetrics(os.Getenv("IONOS_TOKEN"), fmt.Sprintf("%s{postgres_cluster=\"%s\"}", metricConfig.Name, *clusters.Id))
+			telemetryResp, err := fetchTelemetryMetrics(ctx, account.Token(), fmt.Sprintf("%s{postgres_cluster=\"%s\"}", metricConfig.Name, *clusters.Id))
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to fetch telemetry metrics for cluster %s: %v\n", *clusters.Id, err)
 				continue
@@ -110,13 +105,13 @@ func processCluster(apiClient *psql.APIClient, m *sync.RWMutex, metrics []Metric
 		}
 	}
 	m.Lock()
-	IonosPostgresClusters = newIonosPostgresResources
+	IonosPostgresClusters[account.Name] = newIonosPostgresResources
 	m.Unlock()
 
 }
 
-func fetchClusters(apiClient *psql.APIClient) (*psql.ClusterList, error) {
-	datacenters, resp, err := apiClient.ClustersApi.ClustersGet(context.Background()).Execute()
+func fetchClusters(ctx context.Context, apiClient *psql.APIClient) (*psql.ClusterList, error) {
+	datacenters, resp, err := apiClient.ClustersApi.ClustersGet(ctx).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling ClustersApi: %v\n", err)
 		if resp != nil {
@@ -134,8 +129,8 @@ func fetchClusters(apiClient *psql.APIClient) (*psql.ClusterList, error) {
 	return &datacenters, nil
 }
 
-func fetchDatabases(apiClient *psql.APIClient, clusterID string) ([]string, error) {
-	databases, resp, err := apiClient.DatabasesApi.DatabasesList(context.Background(), clusterID).Execute()
+func fetchDatabases(ctx context.Context, apiClient *psql.APIClient, clusterID string) ([]string, error) {
+	databases, resp, err := apiClient.DatabasesApi.DatabasesList(ctx, clusterID).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling DatabasesApi: %v\n", err)
 		if resp != nil {
@@ -160,8 +155,8 @@ func fetchDatabases(apiClient *psql.APIClient, clusterID string) ([]string, erro
 	return databaseNames, nil
 }
 
-func fetchOwner(apiClient *psql.APIClient, clusterID string) (string, error) {
-	databases, resp, err := apiClient.DatabasesApi.DatabasesList(context.Background(), clusterID).Execute()
+func fetchOwner(ctx context.Context, apiClient *psql.APIClient, clusterID string) (string, error) {
+	databases, resp, err := apiClient.DatabasesApi.DatabasesList(ctx, clusterID).Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error when calling DatabasesApi: %v\n", err)
 		if resp != nil {
@@ -184,8 +179,8 @@ func fetchOwner(apiClient *psql.APIClient, clusterID string) (string, error) {
 	return owner, nil
 }
 
-func fetchTelemetryMetrics(apiToken, query string) (*TelemetryResponse, error) {
-	req, err := http.NewRequest("GET", "https://dcd.ionos.com/telemetry/api/v1/query_range", nil)
+func fetchTelemetryMetrics(ctx context.Context, apiToken, query string) (*TelemetryResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://dcd.ionos.com/telemetry/api/v1/query_range", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -24,18 +24,46 @@ func (collector *postgresCollector) GetMutex() *sync.RWMutex {
 	return collector.mutex
 }
 
-func StartPrometheus(m *sync.RWMutex) {
-	dcMutex := &sync.RWMutex{}
-	s3Mutex := &sync.RWMutex{}
-	pgMutex := &sync.RWMutex{}
+func (collector *storageCollector) GetMutex() *sync.RWMutex {
+	return collector.mutex
+}
+
+func (collector *k8sCollector) GetMutex() *sync.RWMutex {
+	return collector.mutex
+}
+
+func (collector *networkCollector) GetMutex() *sync.RWMutex {
+	return collector.mutex
+}
+
+func (collector *connectivityCollector) GetMutex() *sync.RWMutex {
+	return collector.mutex
+}
+
+func (collector *requestsCollector) GetMutex() *sync.RWMutex {
+	return collector.mutex
+}
 
-	ionosCollector := NewIonosCollector(dcMutex)
-	s3Collector := NewS3Collector(s3Mutex)
-	pgCollector := NewPostgresCollector(pgMutex)
+// The collectors share the mutex the scrapers hold while they replace the entries of an account,
+// so a scrape never reads a map which is being written
+func StartPrometheus(m *sync.RWMutex, config *Config) {
+	ionosCollector := NewIonosCollector(m)
+	s3Collector := NewS3Collector(m, config.Collectors.S3)
+	pgCollector := NewPostgresCollector(m)
+	storageCollector := NewStorageCollector(m)
+	k8sCollector := NewK8sCollector(m)
+	networkCollector := NewNetworkCollector(m)
+	connectivityCollector := NewConnectivityCollector(m)
+	requestsCollector := NewRequestsCollector(m)
 
 	prometheus.MustRegister(ionosCollector)
 	prometheus.MustRegister(s3Collector)
 	prometheus.MustRegister(pgCollector)
+	prometheus.MustRegister(storageCollector)
+	prometheus.MustRegister(k8sCollector)
+	prometheus.MustRegister(networkCollector)
+	prometheus.MustRegister(connectivityCollector)
+	prometheus.MustRegister(requestsCollector)
 	prometheus.MustRegister(HttpRequestsTotal)
 }
 
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// One line of the S3 server access log, fields which are "-" in the log are empty or 0
+type s3LogEntry struct {
+	BucketOwner    string
+	Bucket         string
+	Time           time.Time
+	RemoteIp       string
+	Requester      string
+	RequestId      string
+	Operation      string // e.g. REST.GET.OBJECT
+	Key            string
+	Method         string // HTTP method of the request URI
+	Status         int
+	ErrorCode      string
+	BytesSent      int64
+	ObjectSize     int64
+	TotalTime      time.Duration // Time from receiving the request until the last byte of the response was sent
+	TurnAroundTime time.Duration // Time S3 spent processing the request
+	Referer        string
+	UserAgent      string
+}
+
+// Number of fields up to and including the turn-around time, later fields are optional
+const s3LogMinFields = 15
+
+const s3LogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+/*
+Splits a line of the S3 server access log into its fields.
+Fields are separated by spaces, the time is enclosed in brackets and the request URI,
+referer and user agent are enclosed in double quotes and may contain spaces.
+
+Parameters:
+  - line: one line of the access log
+
+Returns:
+  - []string: the fields without brackets and quotes
+*/
+func tokenizeS3LogLine(line string) []string {
+	line = strings.TrimRight(line, "\r\n")
+	var fields []string
+	for i := 0; i < len(line); {
+		var closing byte
+		switch line[i] {
+		case ' ':
+			i++
+			continue
+		case '"':
+			closing = '"'
+		case '[':
+			closing = ']'
+		}
+		if closing != 0 {
+			end := strings.IndexByte(line[i+1:], closing)
+			if end < 0 {
+				end = len(line) - i - 1
+			}
+			fields = append(fields, line[i+1:i+1+end])
+			i += end + 2
+			continue
+		}
+		end := strings.IndexByte(line[i:], ' ')
+		if end < 0 {
+			end = len(line) - i
+		}
+		fields = append(fields, line[i:i+end])
+		i += end
+	}
+	return fields
+}
+
+/*
+Parses a line of the S3 server access log
+
+Parameters:
+  - line: one line of the access log
+
+Returns:
+  - s3LogEntry: the parsed entry, or an error if the line is not a valid log entry
+*/
+func parseS3LogLine(line string) (s3LogEntry, error) {
+	fields := tokenizeS3LogLine(line)
+	if len(fields) < s3LogMinFields {
+		return s3LogEntry{}, fmt.Errorf("expected at least %d fields, got %d", s3LogMinFields, len(fields))
+	}
+	for i, field := range fields {
+		if field == "-" {
+			fields[i] = ""
+		}
+	}
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	entry := s3LogEntry{
+		BucketOwner: fields[0],
+		Bucket:      fields[1],
+		RemoteIp:    fields[3],
+		Requester:   fields[4],
+		RequestId:   fields[5],
+		Operation:   fields[6],
+		Key:         fields[7],
+		ErrorCode:   fields[10],
+		Referer:     field(15),
+		UserAgent:   field(16),
+	}
+	var err error
+	if entry.Time, err = time.Parse(s3LogTimeFormat, fields[2]); err != nil {
+		return s3LogEntry{}, fmt.Errorf("invalid time %q: %v", fields[2], err)
+	}
+	if method, _, found := strings.Cut(fields[8], " "); found {
+		entry.Method = method
+	}
+	if fields[9] != "" {
+		if entry.Status, err = strconv.Atoi(fields[9]); err != nil {
+			return s3LogEntry{}, fmt.Errorf("invalid HTTP status %q", fields[9])
+		}
+	}
+	if entry.BytesSent, err = parseS3LogNumber(fields[11]); err != nil {
+		return s3LogEntry{}, err
+	}
+	if entry.ObjectSize, err = parseS3LogNumber(fields[12]); err != nil {
+		return s3LogEntry{}, err
+	}
+	totalTime, err := parseS3LogNumber(fields[13])
+	if err != nil {
+		return s3LogEntry{}, err
+	}
+	turnAroundTime, err := parseS3LogNumber(fields[14])
+	if err != nil {
+		return s3LogEntry{}, err
+	}
+	entry.TotalTime = time.Duration(totalTime) * time.Millisecond
+	entry.TurnAroundTime = time.Duration(turnAroundTime) * time.Millisecond
+	return entry, nil
+}
+
+func parseS3LogNumber(field string) (int64, error) {
+	if field == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", field)
+	}
+	return number, nil
+}
+
+// HTTP status class of the request, e.g. 4xx, or unknown if the log has no status
+func (entry s3LogEntry) StatusClass() string {
+	if entry.Status < 100 || entry.Status > 599 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%dxx", entry.Status/100)
+}
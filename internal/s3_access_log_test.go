@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	awsLogLine = `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be 3E57427F3EXAMPLE REST.GET.VERSIONING - "GET /awsexamplebucket1?versioning HTTP/1.1" 200 - 113 - 7 - "-" "S3Console/0.4" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV2 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSV1.1`
+	putLogLine = `owner-id images [17/Oct/2026:08:15:02 +0200] 198.51.100.7 user-id 4F2A9C1E REST.PUT.OBJECT photos/cat.jpg "PUT /images/photos/cat.jpg HTTP/1.1" 200 - - 1048576 120 95 "https://example.com/upload" "aws-cli/2.13.0 Python/3.11.4 Linux/6.1 exe/x86_64"`
+	// Written by S3 itself, e.g. lifecycle expirations, so most fields are empty
+	lifecycleLogLine = `owner-id images [17/Oct/2026:00:00:00 +0000] - - 7C1D0E2B S3.EXPIRE.OBJECT photos/old.jpg "-" - - - 2048 - -`
+)
+
+func TestParseS3LogLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    s3LogEntry
+		wantErr string
+	}{
+		{
+			name: "aws example",
+			line: awsLogLine,
+			want: s3LogEntry{
+				BucketOwner: "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+				Bucket:      "awsexamplebucket1",
+				Time:        time.Date(2019, time.February, 6, 0, 0, 38, 0, time.UTC),
+				RemoteIp:    "192.0.2.3",
+				Requester:   "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+				RequestId:   "3E57427F3EXAMPLE",
+				Operation:   "REST.GET.VERSIONING",
+				Method:      "GET",
+				Status:      200,
+				BytesSent:   113,
+				TotalTime:   7 * time.Millisecond,
+				UserAgent:   "S3Console/0.4",
+			},
+		},
+		{
+			name: "user agent with spaces",
+			line: putLogLine,
+			want: s3LogEntry{
+				BucketOwner:    "owner-id",
+				Bucket:         "images",
+				Time:           time.Date(2026, time.October, 17, 6, 15, 2, 0, time.UTC),
+				RemoteIp:       "198.51.100.7",
+				Requester:      "user-id",
+				RequestId:      "4F2A9C1E",
+				Operation:      "REST.PUT.OBJECT",
+				Key:            "photos/cat.jpg",
+				Method:         "PUT",
+				Status:         200,
+				ObjectSize:     1048576,
+				TotalTime:      120 * time.Millisecond,
+				TurnAroundTime: 95 * time.Millisecond,
+				Referer:        "https://example.com/upload",
+				UserAgent:      "aws-cli/2.13.0 Python/3.11.4 Linux/6.1 exe/x86_64",
+			},
+		},
+		{
+			name: "placeholders",
+			line: lifecycleLogLine,
+			want: s3LogEntry{
+				BucketOwner: "owner-id",
+				Bucket:      "images",
+				Time:        time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+				RequestId:   "7C1D0E2B",
+				Operation:   "S3.EXPIRE.OBJECT",
+				Key:         "photos/old.jpg",
+				ObjectSize:  2048,
+			},
+		},
+		{
+			name:    "truncated line",
+			line:    awsLogLine[:strings.Index(awsLogLine, " 200 ")],
+			wantErr: "expected at least 15 fields",
+		},
+		{
+			name:    "invalid time",
+			line:    strings.Replace(putLogLine, "17/Oct/2026:08:15:02 +0200", "yesterday", 1),
+			wantErr: "invalid time",
+		},
+		{
+			name:    "invalid number",
+			line:    strings.Replace(putLogLine, " 1048576 ", " 1MB ", 1),
+			wantErr: "invalid number",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseS3LogLine(test.line)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Time.Equal(test.want.Time) {
+				t.Errorf("time: expected %v, got %v", test.want.Time, got.Time)
+			}
+			got.Time, test.want.Time = time.Time{}, time.Time{}
+			if got != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS3LogEntryStatusClass(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{200, "2xx"},
+		{404, "4xx"},
+		{503, "5xx"},
+		{0, "unknown"},
+	}
+	for _, test := range tests {
+		if got := (s3LogEntry{Status: test.status}).StatusClass(); got != test.want {
+			t.Errorf("status %d: expected %s, got %s", test.status, test.want, got)
+		}
+	}
+}
+
+func TestProcessLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		sourceBucket string
+		wantRequests int64
+		wantErrors   int64
+	}{
+		{name: "same bucket", line: putLogLine, sourceBucket: "images", wantRequests: 1},
+		{name: "different bucket", line: putLogLine, sourceBucket: "videos"},
+		{name: "truncated line", line: putLogLine[:40], sourceBucket: "images", wantErrors: 1},
+		{name: "empty line", line: "  ", sourceBucket: "images"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := newMetrics("account", "endpoint")
+			processLine([]byte(test.line), test.sourceBucket, &metrics, S3TopNConfig{Limit: 10, KeyPrefixDepth: 1, IPv4PrefixLength: 24, IPv6PrefixLength: 48})
+			if got := metrics.Methods["PUT"]; got != test.wantRequests {
+				t.Errorf("PUT requests: expected %d, got %d", test.wantRequests, got)
+			}
+			if metrics.ParseErrors != test.wantErrors {
+				t.Errorf("parse errors: expected %d, got %d", test.wantErrors, metrics.ParseErrors)
+			}
+		})
+	}
+}
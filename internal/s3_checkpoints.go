@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	aws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	s3Checkpoints   = make(map[string]s3Checkpoint) // Last processed log object, keyed by account, endpoint and bucket
+	checkpointMutex sync.Mutex
+	saveMutex       sync.Mutex        // Held while saving, so an older snapshot never overwrites a newer one
+	checkpointStore s3CheckpointStore // nil if the checkpoints are only kept in memory
+)
+
+// The last processed log object together with the location it was read from
+type s3Checkpoint struct {
+	Bucket string `json:"bucket"` // Bucket the logs are stored in, empty for checkpoints saved by older versions
+	Prefix string `json:"prefix"`
+	Key    string `json:"key"`
+}
+
+// Older versions stored only the key of the object as string
+func (checkpoint *s3Checkpoint) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*checkpoint = s3Checkpoint{Key: key}
+		return nil
+	}
+	type plain s3Checkpoint
+	return json.Unmarshal(data, (*plain)(checkpoint))
+}
+
+/*
+Returns the key to continue the listing of a log location after
+
+Parameters:
+  - location: the bucket and prefix the logs are currently stored in
+
+Returns:
+  - string: key of the last processed log object, empty if the checkpoint belongs to another location
+*/
+func (checkpoint s3Checkpoint) startAfter(location s3LogLocation) string {
+	if checkpoint.Bucket == "" {
+		// Saved by an older version, only the prefix can be compared
+		if strings.HasPrefix(checkpoint.Key, location.Prefix) {
+			return checkpoint.Key
+		}
+		return ""
+	}
+	if checkpoint.Bucket != location.Bucket || checkpoint.Prefix != location.Prefix {
+		return ""
+	}
+	return checkpoint.Key
+}
+
+// Persists the checkpoints, so log objects are not processed again after a restart
+type s3CheckpointStore interface {
+	load(ctx context.Context) ([]byte, error) // Returns nil if no checkpoints were stored yet
+	save(ctx context.Context, data []byte) error
+}
+
+type fileCheckpointStore struct {
+	path string
+}
+
+func (store fileCheckpointStore) load(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Writes to a temporary file first, so an interrupted write does not destroy the checkpoints
+func (store fileCheckpointStore) save(ctx context.Context, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path)
+}
+
+type s3ObjectCheckpointStore struct {
+	client *s3.S3
+	bucket string
+	key    string
+}
+
+func (store s3ObjectCheckpointStore) load(ctx context.Context) ([]byte, error) {
+	result, err := store.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(store.bucket),
+		Key:    aws.String(store.key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer result.Body.Close()
+	return io.ReadAll(result.Body)
+}
+
+func (store s3ObjectCheckpointStore) save(ctx context.Context, data []byte) error {
+	_, err := store.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(store.bucket),
+		Key:         aws.String(store.key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+/*
+Creates the configured checkpoint store and loads the checkpoints saved by a previous run of the exporter
+
+Parameters:
+  - config: configuration of the S3 collector
+
+Returns:
+  - error: An error if the store can not be created or the stored checkpoints can not be read
+*/
+func LoadS3Checkpoints(config S3CollectorConfig) error {
+	checkpoint := config.Checkpoint
+	var store s3CheckpointStore
+	switch {
+	case checkpoint.File != "":
+		store = fileCheckpointStore{path: checkpoint.File}
+	case checkpoint.Bucket != "":
+		for _, endpoint := range config.Endpoints {
+			if endpoint.Name != checkpoint.Endpoint {
+				continue
+			}
+			accessKey, secretKey := endpoint.Credentials(AccountConfig{})
+			client, err := createS3ServiceClient(endpoint, accessKey, secretKey)
+			if err != nil {
+				return err
+			}
+			store = s3ObjectCheckpointStore{client: client, bucket: checkpoint.Bucket, key: checkpoint.Key}
+		}
+	default:
+		log.Println("No checkpoint store configured, S3 log objects are processed again after a restart")
+		return nil
+	}
+
+	data, err := store.load(context.Background())
+	if err != nil {
+		return fmt.Errorf("cannot load S3 checkpoints: %v", err)
+	}
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	if data != nil {
+		if err := json.Unmarshal(data, &s3Checkpoints); err != nil {
+			return fmt.Errorf("cannot parse S3 checkpoints: %v", err)
+		}
+	}
+	checkpointStore = store
+	return nil
+}
+
+// Stores the current checkpoints, errors are logged and the checkpoints are saved again after the next cycle
+func saveS3Checkpoints() {
+	if checkpointStore == nil {
+		return
+	}
+	saveMutex.Lock()
+	defer saveMutex.Unlock()
+	checkpointMutex.Lock()
+	data, err := json.Marshal(s3Checkpoints)
+	checkpointMutex.Unlock()
+	if err != nil {
+		log.Printf("Error encoding S3 checkpoints: %v\n", err)
+		return
+	}
+	if err := checkpointStore.save(context.Background(), data); err != nil {
+		log.Printf("Error saving S3 checkpoints: %v\n", err)
+	}
+}
+
+func s3CheckpointKey(account string, endpoint string, bucketName string) string {
+	return account + "/" + endpoint + "/" + bucketName
+}
+
+func getS3Checkpoint(key string) s3Checkpoint {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	return s3Checkpoints[key]
+}
+
+func setS3Checkpoint(key string, location s3LogLocation, lastObject string) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	s3Checkpoints[key] = s3Checkpoint{Bucket: location.Bucket, Prefix: location.Prefix, Key: lastObject}
+}
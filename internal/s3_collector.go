@@ -2,96 +2,153 @@ package internal
 
 import (
 	"sync"
-
-	//"time"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Labels of every S3 metric, they identify the bucket
+var s3BucketLabels = []string{"account", "endpoint", "bucket"}
+
+// Metadata of the bucket, only exported by ionos_s3_bucket_info followed by the configured tag labels.
+// Keeping it off the other metrics means a changed owner or tag does not start new series.
+var s3InfoLabels = []string{"region", "owner"}
+
+// The access log statistics only grow, so they are exported as counters and histograms.
+// The traffic breakdowns are the exception, an entry moving in or out of the top N changes the other entry, so they are gauges.
 type s3Collector struct {
-	mutex                             *sync.RWMutex
-	s3TotalGetRequestSizeMetric       *prometheus.GaugeVec
-	s3TotalGetResponseSizeMetric      *prometheus.GaugeVec
-	s3TotalPutRequestSizeMetric       *prometheus.GaugeVec
-	s3TotalPutResponseSizeMetric      *prometheus.GaugeVec
-	s3TotalPostRequestSizeMetric      *prometheus.GaugeVec
-	s3TotalPostResponseSizeMetric     *prometheus.GaugeVec
-	s3TotalHeadRequestSizeMetric      *prometheus.GaugeVec
-	s3TotalHeadResponseSizeMetric     *prometheus.GaugeVec
-	s3TotalNumberOfGetRequestsMetric  *prometheus.GaugeVec
-	s3TotalNumberOfPutRequestsMetric  *prometheus.GaugeVec
-	s3TotalNumberOfPostRequestsMetric *prometheus.GaugeVec
-	s3TotalNumberOfHeadRequestsMetric *prometheus.GaugeVec
+	mutex                    *sync.RWMutex
+	tagLabels                []S3TagLabelConfig
+	s3BucketInfoDesc         *prometheus.Desc
+	s3RequestsDesc           *prometheus.Desc
+	s3RequestSizeBytesDesc   *prometheus.Desc
+	s3ResponseSizeBytesDesc  *prometheus.Desc
+	s3OperationsDesc         *prometheus.Desc
+	s3RequestDurationDesc    *prometheus.Desc
+	s3TurnAroundDurationDesc *prometheus.Desc
+	s3LogParseErrorsDesc     *prometheus.Desc
+
+	s3BucketSizeDesc        *prometheus.Desc
+	s3BucketObjectsDesc     *prometheus.Desc
+	s3BucketUsageListedDesc *prometheus.Desc
+
+	s3BucketPostureDesc *prometheus.Desc
+
+	s3MultipartUploadsDesc *prometheus.Desc
+	s3MultipartOldestDesc  *prometheus.Desc
+	s3MultipartBytesDesc   *prometheus.Desc
+
+	topNLimit                    int // Number of entries per breakdown, 0 if the breakdowns are disabled
+	s3RequesterRequestsDesc      *prometheus.Desc
+	s3RequesterResponseBytesDesc *prometheus.Desc
+	s3NetworkRequestsDesc        *prometheus.Desc
+	s3NetworkResponseBytesDesc   *prometheus.Desc
+	s3KeyPrefixRequestsDesc      *prometheus.Desc
+	s3KeyPrefixResponseBytesDesc *prometheus.Desc
 }
 
-func NewS3Collector(m *sync.RWMutex) *s3Collector {
+func NewS3Collector(m *sync.RWMutex, config S3CollectorConfig) *s3Collector {
+	infoLabels := append([]string{}, s3InfoLabels...)
+	for _, tagLabel := range config.TagLabels {
+		infoLabels = append(infoLabels, tagLabel.Label)
+	}
 	return &s3Collector{
-		mutex: m,
-		s3TotalGetRequestSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_get_request_size_in_bytes",
-			Help: "Gives the total size of s3 GET Request in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalGetResponseSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_get_response_size_in_bytes",
-			Help: "Gives the total size of s3 GET Response in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalPutRequestSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_put_request_size_in_bytes",
-			Help: "Gives the total size of s3 PUT Request in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalPutResponseSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_put_response_size_in_bytes",
-			Help: "Gives the total size of s3 PUT Response in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalPostRequestSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_post_request_size_in_bytes",
-			Help: "Gives the total size of s3 POST Request in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalPostResponseSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_post_response_size_in_bytes",
-			Help: "Gives the total size of s3 POST Response in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalHeadRequestSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_head_request_size_in_bytes",
-			Help: "Gives the total size of s3 HEAD Request in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalHeadResponseSizeMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_head_response_size_in_bytes",
-			Help: "Gives the total size of s3 HEAD Response in Bytes in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalNumberOfGetRequestsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_number_of_get_requests",
-			Help: "Gives the total number of S3 GET HTTP Requests in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalNumberOfPutRequestsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_number_of_put_requests",
-			Help: "Gives the total number of S3 PUT HTTP Requests in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalNumberOfPostRequestsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_number_of_post_requests",
-			Help: "Gives the total number of S3 Post Requests in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
-		s3TotalNumberOfHeadRequestsMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "s3_total_number_of_head_requests",
-			Help: "Gives the total number of S3 HEAD HTTP Requests in one Bucket",
-		}, []string{"bucket", "method", "region", "owner", "enviroment", "namespace", "tenant"}),
+		mutex:     m,
+		tagLabels: config.TagLabels,
+		topNLimit: config.TopN.Limit,
+		s3BucketInfoDesc: prometheus.NewDesc("ionos_s3_bucket_info",
+			"Region, owner and tags of the bucket, the value is always 1",
+			s3Labels(infoLabels...), nil),
+		s3RequestsDesc: prometheus.NewDesc("ionos_s3_requests_total",
+			"Number of requests to the bucket per HTTP method, counted from the access logs",
+			s3Labels("method"), nil),
+		s3RequestSizeBytesDesc: prometheus.NewDesc("ionos_s3_request_size_bytes_total",
+			"Size of the objects of the requests to the bucket in bytes per HTTP method, counted from the access logs",
+			s3Labels("method"), nil),
+		s3ResponseSizeBytesDesc: prometheus.NewDesc("ionos_s3_response_size_bytes_total",
+			"Bytes sent in the responses of the bucket per HTTP method, counted from the access logs",
+			s3Labels("method"), nil),
+		s3OperationsDesc: prometheus.NewDesc("ionos_s3_operations_total",
+			"Number of requests to the bucket per S3 operation and HTTP status class, e.g. 4xx",
+			s3Labels("operation", "status_class"), nil),
+		s3RequestDurationDesc: prometheus.NewDesc("ionos_s3_request_duration_seconds",
+			"Total time of the requests to the bucket from receiving the request until the response was sent",
+			s3Labels("method"), nil),
+		s3TurnAroundDurationDesc: prometheus.NewDesc("ionos_s3_turn_around_duration_seconds",
+			"Time S3 spent processing the requests to the bucket",
+			s3Labels("method"), nil),
+		s3LogParseErrorsDesc: prometheus.NewDesc("ionos_s3_log_parse_errors_total",
+			"Number of access log lines of the bucket which could not be parsed",
+			s3Labels(), nil),
+		s3BucketSizeDesc: prometheus.NewDesc("ionos_s3_bucket_size_bytes",
+			"Size of the objects of the bucket in bytes per storage class, including all versions if versioning is enabled",
+			s3Labels("storage_class"), nil),
+		s3BucketObjectsDesc: prometheus.NewDesc("ionos_s3_bucket_objects_amount",
+			"Number of objects of the bucket per storage class, including all versions if versioning is enabled",
+			s3Labels("storage_class"), nil),
+		s3BucketUsageListedDesc: prometheus.NewDesc("ionos_s3_bucket_usage_timestamp_seconds",
+			"Time of the listing the size and number of objects of the bucket are based on",
+			s3Labels(), nil),
+		s3BucketPostureDesc: prometheus.NewDesc("ionos_s3_bucket_posture",
+			"1 if the security relevant setting named by check is present on the bucket, 0 if not, e.g. acl_all_users or encryption",
+			s3Labels("check"), nil),
+		s3MultipartUploadsDesc: prometheus.NewDesc("ionos_s3_multipart_uploads_amount",
+			"Number of incomplete multipart uploads of the bucket",
+			s3Labels(), nil),
+		s3MultipartOldestDesc: prometheus.NewDesc("ionos_s3_multipart_oldest_upload_age_seconds",
+			"Age of the oldest incomplete multipart upload of the bucket, 0 if there is none",
+			s3Labels(), nil),
+		s3MultipartBytesDesc: prometheus.NewDesc("ionos_s3_multipart_uploads_size_bytes",
+			"Estimated size of the parts of the incomplete multipart uploads of the bucket in bytes, extrapolated from the first uploads in key order, missing if none of them could be listed",
+			s3Labels(), nil),
+		s3RequesterRequestsDesc: prometheus.NewDesc("ionos_s3_requester_requests",
+			"Number of requests to the bucket of the requesters with the most bytes sent, the rest is summed up as other",
+			s3Labels("requester"), nil),
+		s3RequesterResponseBytesDesc: prometheus.NewDesc("ionos_s3_requester_response_size_bytes",
+			"Bytes sent to the requesters with the most bytes sent, the rest is summed up as other",
+			s3Labels("requester"), nil),
+		s3NetworkRequestsDesc: prometheus.NewDesc("ionos_s3_remote_network_requests",
+			"Number of requests to the bucket from the remote networks with the most bytes sent, the rest is summed up as other",
+			s3Labels("network"), nil),
+		s3NetworkResponseBytesDesc: prometheus.NewDesc("ionos_s3_remote_network_response_size_bytes",
+			"Bytes sent to the remote networks with the most bytes sent, the rest is summed up as other",
+			s3Labels("network"), nil),
+		s3KeyPrefixRequestsDesc: prometheus.NewDesc("ionos_s3_key_prefix_requests",
+			"Number of requests to the key prefixes of the bucket with the most bytes sent, the rest is summed up as other",
+			s3Labels("prefix"), nil),
+		s3KeyPrefixResponseBytesDesc: prometheus.NewDesc("ionos_s3_key_prefix_response_size_bytes",
+			"Bytes sent from the key prefixes of the bucket with the most bytes sent, the rest is summed up as other",
+			s3Labels("prefix"), nil),
 	}
 }
 
-func (collector *s3Collector) Describe(ch chan<- *prometheus.Desc) {
-	collector.s3TotalGetRequestSizeMetric.Describe(ch)
-	collector.s3TotalGetResponseSizeMetric.Describe(ch)
-	collector.s3TotalPutRequestSizeMetric.Describe(ch)
-	collector.s3TotalPutResponseSizeMetric.Describe(ch)
-	collector.s3TotalPostRequestSizeMetric.Describe(ch)
-	collector.s3TotalPostResponseSizeMetric.Describe(ch)
-	collector.s3TotalHeadRequestSizeMetric.Describe(ch)
-	collector.s3TotalHeadResponseSizeMetric.Describe(ch)
-	collector.s3TotalNumberOfGetRequestsMetric.Describe(ch)
-	collector.s3TotalNumberOfPutRequestsMetric.Describe(ch)
-	collector.s3TotalNumberOfPostRequestsMetric.Describe(ch)
-	collector.s3TotalNumberOfHeadRequestsMetric.Describe(ch)
+// Labels of the bucket followed by the given labels
+func s3Labels(labels ...string) []string {
+	return append(append([]string{}, s3BucketLabels...), labels...)
+}
 
+func (collector *s3Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.s3BucketInfoDesc
+	ch <- collector.s3RequestsDesc
+	ch <- collector.s3RequestSizeBytesDesc
+	ch <- collector.s3ResponseSizeBytesDesc
+	ch <- collector.s3OperationsDesc
+	ch <- collector.s3RequestDurationDesc
+	ch <- collector.s3TurnAroundDurationDesc
+	ch <- collector.s3LogParseErrorsDesc
+	ch <- collector.s3BucketSizeDesc
+	ch <- collector.s3BucketObjectsDesc
+	ch <- collector.s3BucketUsageListedDesc
+	ch <- collector.s3BucketPostureDesc
+	ch <- collector.s3MultipartUploadsDesc
+	ch <- collector.s3MultipartOldestDesc
+	ch <- collector.s3MultipartBytesDesc
+	ch <- collector.s3RequesterRequestsDesc
+	ch <- collector.s3RequesterResponseBytesDesc
+	ch <- collector.s3NetworkRequestsDesc
+	ch <- collector.s3NetworkResponseBytesDesc
+	ch <- collector.s3KeyPrefixRequestsDesc
+	ch <- collector.s3KeyPrefixResponseBytesDesc
 }
 
 func (collector *s3Collector) Collect(ch chan<- prometheus.Metric) {
@@ -100,83 +157,85 @@ func (collector *s3Collector) Collect(ch chan<- prometheus.Metric) {
 	defer collector.mutex.RUnlock()
 
 	metricsMutex.Lock()
-	collector.s3TotalGetRequestSizeMetric.Reset()
-	collector.s3TotalGetResponseSizeMetric.Reset()
-	collector.s3TotalPutRequestSizeMetric.Reset()
-	collector.s3TotalPutResponseSizeMetric.Reset()
-	collector.s3TotalPostRequestSizeMetric.Reset()
-	collector.s3TotalPostResponseSizeMetric.Reset()
-	collector.s3TotalHeadRequestSizeMetric.Reset()
-	collector.s3TotalHeadResponseSizeMetric.Reset()
-	collector.s3TotalNumberOfGetRequestsMetric.Reset()
-	collector.s3TotalNumberOfPutRequestsMetric.Reset()
-	collector.s3TotalNumberOfPostRequestsMetric.Reset()
-	collector.s3TotalNumberOfHeadRequestsMetric.Reset()
-
 	defer metricsMutex.Unlock()
-	for s3Name, s3Resources := range IonosS3Buckets {
-		region := s3Resources.Regions
-		owner := s3Resources.Owner
-		tags := TagsForPrometheus[s3Name]
-		// if !ok {
-		// 	// fmt.Printf("No tags found for bucket %s\n", s3Name)
-		// }
-		//tags of buckets change to tags you have defined on s3 buckets
-		enviroment := tags["Enviroment"]
-		namespace := tags["Namespace"]
-		tenant := tags["Tenant"]
+	for key, s3Resources := range IonosS3Buckets {
+		tags := TagsForPrometheus[key]
+		labels := func(values ...string) []string {
+			return append([]string{key.Account, key.Endpoint, key.Bucket}, values...)
+		}
+		infoValues := []string{s3Resources.Regions, s3Resources.Owner}
+		for _, tagLabel := range collector.tagLabels {
+			infoValues = append(infoValues, tagLabel.Value(tags))
+		}
+		ch <- prometheus.MustNewConstMetric(collector.s3BucketInfoDesc, prometheus.GaugeValue, 1, labels(infoValues...)...)
+
+		for method, count := range s3Resources.Methods {
+			ch <- prometheus.MustNewConstMetric(collector.s3RequestsDesc, prometheus.CounterValue, float64(count), labels(method)...)
+		}
 		for method, requestSize := range s3Resources.RequestSizes {
+			ch <- prometheus.MustNewConstMetric(collector.s3RequestSizeBytesDesc, prometheus.CounterValue, float64(requestSize), labels(method)...)
+		}
+		for method, responseSize := range s3Resources.ResponseSizes {
+			ch <- prometheus.MustNewConstMetric(collector.s3ResponseSizeBytesDesc, prometheus.CounterValue, float64(responseSize), labels(method)...)
+		}
+		for key, count := range s3Resources.Operations {
+			ch <- prometheus.MustNewConstMetric(collector.s3OperationsDesc, prometheus.CounterValue, float64(count), labels(key.Operation, key.StatusClass)...)
+		}
+		for method, histogram := range s3Resources.TotalTimes {
+			ch <- prometheus.MustNewConstHistogram(collector.s3RequestDurationDesc, histogram.Count, histogram.Sum, histogram.buckets(), labels(method)...)
+		}
+		for method, histogram := range s3Resources.TurnAroundTimes {
+			ch <- prometheus.MustNewConstHistogram(collector.s3TurnAroundDurationDesc, histogram.Count, histogram.Sum, histogram.buckets(), labels(method)...)
+		}
+		ch <- prometheus.MustNewConstMetric(collector.s3LogParseErrorsDesc, prometheus.CounterValue, float64(s3Resources.ParseErrors), labels()...)
 
-			switch method {
-			case MethodGET:
-				collector.s3TotalGetRequestSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(requestSize))
-			case MethodPOST:
-				collector.s3TotalPostRequestSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(requestSize))
-			case MethodHEAD:
-				collector.s3TotalHeadRequestSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(requestSize))
-			case MethodPUT:
-				collector.s3TotalPutRequestSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(requestSize))
+		if usage, exists := IonosS3Usage[key]; exists {
+			for storageClass, size := range usage.Bytes {
+				ch <- prometheus.MustNewConstMetric(collector.s3BucketSizeDesc, prometheus.GaugeValue, float64(size), labels(storageClass)...)
 			}
-
+			for storageClass, objects := range usage.Objects {
+				ch <- prometheus.MustNewConstMetric(collector.s3BucketObjectsDesc, prometheus.GaugeValue, float64(objects), labels(storageClass)...)
+			}
+			ch <- prometheus.MustNewConstMetric(collector.s3BucketUsageListedDesc, prometheus.GaugeValue, float64(usage.LastListed.Unix()), labels()...)
 		}
-		for method, responseSize := range s3Resources.ResponseSizes {
 
-			switch method {
-			case MethodGET:
-				collector.s3TotalGetResponseSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodPOST:
-				collector.s3TotalPostResponseSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodHEAD:
-				collector.s3TotalHeadResponseSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodPUT:
-				collector.s3TotalPutResponseSizeMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
+		for check, present := range IonosS3Posture[key] {
+			value := 0.0
+			if present {
+				value = 1
 			}
+			ch <- prometheus.MustNewConstMetric(collector.s3BucketPostureDesc, prometheus.GaugeValue, value, labels(check)...)
 		}
 
-		for method, responseSize := range s3Resources.Methods {
-			switch method {
-			case MethodGET:
-				collector.s3TotalNumberOfGetRequestsMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodPOST:
-				collector.s3TotalNumberOfPostRequestsMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodHEAD:
-				collector.s3TotalNumberOfHeadRequestsMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
-			case MethodPUT:
-				collector.s3TotalNumberOfPutRequestsMetric.WithLabelValues(s3Name, method, region, owner, enviroment, namespace, tenant).Set(float64(responseSize))
+		if uploads, exists := IonosS3Multipart[key]; exists {
+			oldestAge := 0.0
+			if !uploads.Oldest.IsZero() {
+				oldestAge = time.Since(uploads.Oldest).Seconds()
+			}
+			ch <- prometheus.MustNewConstMetric(collector.s3MultipartUploadsDesc, prometheus.GaugeValue, float64(uploads.Count), labels()...)
+			ch <- prometheus.MustNewConstMetric(collector.s3MultipartOldestDesc, prometheus.GaugeValue, oldestAge, labels()...)
+			// Without a sampled upload the size is unknown, unless there are no uploads at all
+			if uploads.Sampled > 0 || uploads.Count == 0 {
+				ch <- prometheus.MustNewConstMetric(collector.s3MultipartBytesDesc, prometheus.GaugeValue, float64(uploads.EstimatedBytes), labels()...)
 			}
 		}
-	}
 
-	collector.s3TotalGetRequestSizeMetric.Collect(ch)
-	collector.s3TotalGetResponseSizeMetric.Collect(ch)
-	collector.s3TotalPutRequestSizeMetric.Collect(ch)
-	collector.s3TotalPutResponseSizeMetric.Collect(ch)
-	collector.s3TotalPostRequestSizeMetric.Collect(ch)
-	collector.s3TotalPostResponseSizeMetric.Collect(ch)
-	collector.s3TotalHeadRequestSizeMetric.Collect(ch)
-	collector.s3TotalHeadResponseSizeMetric.Collect(ch)
-	collector.s3TotalNumberOfGetRequestsMetric.Collect(ch)
-	collector.s3TotalNumberOfPutRequestsMetric.Collect(ch)
-	collector.s3TotalNumberOfPostRequestsMetric.Collect(ch)
-	collector.s3TotalNumberOfHeadRequestsMetric.Collect(ch)
+		if collector.topNLimit == 0 {
+			continue
+		}
+		// The top N are recalculated on every scrape, so the values can decrease
+
+		for _, traffic := range topTraffic(s3Resources.Requesters, collector.topNLimit) {
+			ch <- prometheus.MustNewConstMetric(collector.s3RequesterRequestsDesc, prometheus.GaugeValue, float64(traffic.Requests), labels(traffic.Name)...)
+			ch <- prometheus.MustNewConstMetric(collector.s3RequesterResponseBytesDesc, prometheus.GaugeValue, float64(traffic.BytesSent), labels(traffic.Name)...)
+		}
+		for _, traffic := range topTraffic(s3Resources.RemoteNetworks, collector.topNLimit) {
+			ch <- prometheus.MustNewConstMetric(collector.s3NetworkRequestsDesc, prometheus.GaugeValue, float64(traffic.Requests), labels(traffic.Name)...)
+			ch <- prometheus.MustNewConstMetric(collector.s3NetworkResponseBytesDesc, prometheus.GaugeValue, float64(traffic.BytesSent), labels(traffic.Name)...)
+		}
+		for _, traffic := range topTraffic(s3Resources.KeyPrefixes, collector.topNLimit) {
+			ch <- prometheus.MustNewConstMetric(collector.s3KeyPrefixRequestsDesc, prometheus.GaugeValue, float64(traffic.Requests), labels(traffic.Name)...)
+			ch <- prometheus.MustNewConstMetric(collector.s3KeyPrefixResponseBytesDesc, prometheus.GaugeValue, float64(traffic.BytesSent), labels(traffic.Name)...)
+		}
+	}
 }
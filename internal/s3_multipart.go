@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"time"
+
+	aws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	IonosS3Multipart = make(map[S3BucketKey]S3MultipartUploads) // Guarded by metricsMutex
+)
+
+// Incomplete multipart uploads of a bucket
+type S3MultipartUploads struct {
+	Count          int64     // Number of incomplete uploads
+	Oldest         time.Time // Start of the oldest incomplete upload, zero if there is none
+	EstimatedBytes int64     // Size of the uploaded parts, extrapolated from the sampled uploads
+	Sampled        int64     // Number of uploads whose parts were listed
+}
+
+/*
+Lists the incomplete multipart uploads of a bucket. The parts of at most sampleSize uploads are listed,
+the size of the other uploads is estimated from their average size. The sample is not spread across
+the uploads, it consists of the first uploads in key order whose parts could be listed.
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - bucketName: name of the bucket
+  - sampleSize: maximum number of uploads whose parts are listed
+
+Returns:
+  - S3MultipartUploads: the incomplete uploads, or an error if they could not be listed
+*/
+func fetchMultipartUploads(ctx context.Context, client *s3.S3, bucketName string, sampleSize int) (S3MultipartUploads, error) {
+	var uploads S3MultipartUploads
+	var sampledBytes int64
+	err := client.ListMultipartUploadsPagesWithContext(ctx, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucketName),
+	}, func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, upload := range page.Uploads {
+			uploads.Count++
+			initiated := aws.TimeValue(upload.Initiated)
+			if !initiated.IsZero() && (uploads.Oldest.IsZero() || initiated.Before(uploads.Oldest)) {
+				uploads.Oldest = initiated
+			}
+			if uploads.Sampled >= int64(sampleSize) {
+				continue
+			}
+			size, err := fetchUploadedBytes(ctx, client, bucketName, upload)
+			if err != nil {
+				log.Printf("Error listing the parts of upload %s in bucket %s: %v\n", aws.StringValue(upload.UploadId), bucketName, err)
+				continue
+			}
+			sampledBytes += size
+			uploads.Sampled++
+		}
+		return true
+	})
+	if err != nil {
+		return S3MultipartUploads{}, err
+	}
+	if uploads.Sampled > 0 {
+		uploads.EstimatedBytes = sampledBytes * uploads.Count / uploads.Sampled
+	}
+	return uploads, nil
+}
+
+// Sums up the size of the parts uploaded so far
+func fetchUploadedBytes(ctx context.Context, client *s3.S3, bucketName string, upload *s3.MultipartUpload) (int64, error) {
+	var size int64
+	err := client.ListPartsPagesWithContext(ctx, &s3.ListPartsInput{
+		Bucket:   aws.String(bucketName),
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	}, func(page *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range page.Parts {
+			size += aws.Int64Value(part.Size)
+		}
+		return true
+	})
+	return size, err
+}
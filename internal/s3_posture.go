@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"log"
+
+	aws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	IonosS3Posture = make(map[S3BucketKey]map[string]bool) // Key is the bucket, then the name of the check, guarded by metricsMutex
+)
+
+const (
+	s3AllUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	s3AuthenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+/*
+Checks the security relevant settings of a bucket. Checks whose API call fails are left out,
+so an unknown setting is not reported as missing.
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - bucketName: name of the bucket
+  - acl: the already retrieved ACL of the bucket
+
+Returns:
+  - map[string]bool: result per check, true if the setting is present
+*/
+func bucketPosture(ctx context.Context, client *s3.S3, bucketName string, acl *s3.GetBucketAclOutput) map[string]bool {
+	posture := make(map[string]bool)
+	bucket := aws.String(bucketName)
+
+	posture["acl_all_users"] = false
+	posture["acl_authenticated_users"] = false
+	for _, grant := range acl.Grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		switch aws.StringValue(grant.Grantee.URI) {
+		case s3AllUsersGroup:
+			posture["acl_all_users"] = true
+		case s3AuthenticatedUsersGroup:
+			posture["acl_authenticated_users"] = true
+		}
+	}
+
+	_, err := client.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: bucket})
+	setPostureCheck(posture, bucketName, "policy", err == nil, err, "NoSuchBucketPolicy")
+
+	versioning, err := client.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: bucket})
+	setPostureCheck(posture, bucketName, "versioning", err == nil && aws.StringValue(versioning.Status) == s3.BucketVersioningStatusEnabled, err)
+
+	objectLock, err := client.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	setPostureCheck(posture, bucketName, "object_lock", err == nil && objectLock.ObjectLockConfiguration != nil &&
+		aws.StringValue(objectLock.ObjectLockConfiguration.ObjectLockEnabled) == s3.ObjectLockEnabledEnabled, err, "ObjectLockConfigurationNotFoundError")
+
+	encryption, err := client.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: bucket})
+	setPostureCheck(posture, bucketName, "encryption", err == nil && encryption.ServerSideEncryptionConfiguration != nil &&
+		len(encryption.ServerSideEncryptionConfiguration.Rules) > 0, err, "ServerSideEncryptionConfigurationNotFoundError")
+
+	cors, err := client.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{Bucket: bucket})
+	setPostureCheck(posture, bucketName, "cors", err == nil && len(cors.CORSRules) > 0, err, "NoSuchCORSConfiguration")
+
+	lifecycle, err := client.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: bucket})
+	lifecycleEnabled, expiration := false, false
+	if err == nil {
+		for _, rule := range lifecycle.Rules {
+			if aws.StringValue(rule.Status) != s3.ExpirationStatusEnabled {
+				continue
+			}
+			lifecycleEnabled = true
+			if rule.Expiration != nil || rule.NoncurrentVersionExpiration != nil {
+				expiration = true
+			}
+		}
+	}
+	setPostureCheck(posture, bucketName, "lifecycle", lifecycleEnabled, err, "NoSuchLifecycleConfiguration")
+	setPostureCheck(posture, bucketName, "lifecycle_expiration", expiration, err, "NoSuchLifecycleConfiguration")
+	return posture
+}
+
+// Sets the result of a check, errors with one of the notConfigured codes mean the setting is absent,
+// the check is left out on any other error
+func setPostureCheck(posture map[string]bool, bucketName string, check string, present bool, err error, notConfigured ...string) {
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || !contains(notConfigured, aerr.Code()) {
+			log.Printf("Error checking %s of bucket %s: %v\n", check, bucketName, err)
+			return
+		}
+	}
+	posture[check] = present
+}
@@ -2,14 +2,13 @@ package internal
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 	"sync"
-	"time"
 
 	aws "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -18,25 +17,91 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
-type EndpointConfig struct {
-	Region    string
-	AccessKey string
-	SecretKey string
-	Endpoint  string
-}
-
 var (
-	IonosS3Buckets    = make(map[string]Metrics)
-	TagsForPrometheus = make(map[string]map[string]string)
+	IonosS3Buckets    = make(map[S3BucketKey]Metrics)
+	TagsForPrometheus = make(map[S3BucketKey]map[string]string)
 	metricsMutex      sync.Mutex
 )
 
+// Identifies a bucket, several accounts or endpoints may have buckets with the same name
+type S3BucketKey struct {
+	Account  string
+	Endpoint string // Name of the endpoint the bucket belongs to
+	Bucket   string
+}
+
+// Access log statistics of a bucket, the counts accumulate over all processed log objects
 type Metrics struct {
-	Methods       map[string]int32
-	RequestSizes  map[string]int64
-	ResponseSizes map[string]int64
+	Methods       map[string]int64 // Number of requests per HTTP method
+	RequestSizes  map[string]int64 // Object size in bytes per HTTP method
+	ResponseSizes map[string]int64 // Bytes sent per HTTP method
 	Regions       string
 	Owner         string
+	Account       string
+	Endpoint      string // Name of the endpoint the bucket was found at
+
+	Operations      map[S3OperationKey]int64     // Number of requests per operation and HTTP status class
+	TotalTimes      map[string]*LatencyHistogram // Total time of the requests per HTTP method
+	TurnAroundTimes map[string]*LatencyHistogram // Turn-around time of the requests per HTTP method
+	ParseErrors     int64                        // Number of log lines which could not be parsed
+
+	Requesters     map[string]*S3Traffic // Traffic per requester, anonymous for unauthenticated requests
+	RemoteNetworks map[string]*S3Traffic // Traffic per network of the remote IP
+	KeyPrefixes    map[string]*S3Traffic // Traffic per leading path segments of the object key
+}
+
+type S3Traffic struct {
+	Requests  int64
+	BytesSent int64
+}
+
+type S3OperationKey struct {
+	Operation   string // S3 operation, e.g. REST.GET.OBJECT
+	StatusClass string // HTTP status class, e.g. 2xx
+}
+
+// Upper bounds in seconds of the buckets of the latency histograms
+var s3LatencyBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type LatencyHistogram struct {
+	Count   uint64
+	Sum     float64  // Sum of all observations in seconds
+	Buckets []uint64 // Cumulative count of observations per bound of s3LatencyBounds
+}
+
+func (histogram *LatencyHistogram) observe(seconds float64) {
+	histogram.Count++
+	histogram.Sum += seconds
+	for i, bound := range s3LatencyBounds {
+		if seconds <= bound {
+			histogram.Buckets[i]++
+		}
+	}
+}
+
+func (histogram *LatencyHistogram) add(other *LatencyHistogram) {
+	histogram.Count += other.Count
+	histogram.Sum += other.Sum
+	for i := range histogram.Buckets {
+		histogram.Buckets[i] += other.Buckets[i]
+	}
+}
+
+// Cumulative counts keyed by upper bound, as expected by prometheus.MustNewConstHistogram
+func (histogram *LatencyHistogram) buckets() map[float64]uint64 {
+	buckets := make(map[float64]uint64, len(s3LatencyBounds))
+	for i, bound := range s3LatencyBounds {
+		buckets[bound] = histogram.Buckets[i]
+	}
+	return buckets
+}
+
+// Returns the histogram of the method, it is created if it does not exist yet
+func latencyHistogram(histograms map[string]*LatencyHistogram, method string) *LatencyHistogram {
+	if _, exists := histograms[method]; !exists {
+		histograms[method] = &LatencyHistogram{Buckets: make([]uint64, len(s3LatencyBounds))}
+	}
+	return histograms[method]
 }
 
 const (
@@ -46,14 +111,27 @@ const (
 	MethodHEAD    = "HEAD"
 	objectPerPage = 1000
 	maxConcurrent = 10
+	logBatchSize  = 100 // Log objects processed before the checkpoint advances
 )
 
-func createS3ServiceClient(region, accessKey, secretKey, endpoint string) (*s3.S3, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(region),
-		Credentials: credentials.NewStaticCredentials(accessKey, secretKey, ""),
-		Endpoint:    aws.String(endpoint),
-	})
+func createS3ServiceClient(endpoint S3EndpointConfig, accessKey, secretKey string) (*s3.S3, error) {
+	options := session.Options{
+		Config: aws.Config{
+			Region:           aws.String(endpoint.Region),
+			Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+			Endpoint:         aws.String(endpoint.Endpoint),
+			S3ForcePathStyle: aws.Bool(endpoint.PathStyle),
+		},
+	}
+	if endpoint.CABundle != "" {
+		caBundle, err := os.Open(endpoint.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle of endpoint %s: %s", endpoint.Name, err)
+		}
+		defer caBundle.Close()
+		options.CustomCABundle = caBundle
+	}
+	sess, err := session.NewSessionWithOptions(options)
 	if err != nil {
 		log.Printf("Error establishing session with AWS S3 Endpoint: %v", err)
 		return nil, fmt.Errorf("error establishing session with AWS S3 Endpoint: %s", err)
@@ -61,45 +139,33 @@ func createS3ServiceClient(region, accessKey, secretKey, endpoint string) (*s3.S
 	return s3.New(sess), nil
 }
 
-func S3CollectResources(m *sync.RWMutex, cycletime int32) {
-	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
-
-	if accessKey == "" || secretKey == "" {
-		log.Println("AWS credentials are not set in the environment variables.")
-		return
+func S3CollectResources(m *sync.RWMutex, account AccountConfig, config S3CollectorConfig) {
+	clients := make(map[string]*s3.S3) // Key is the name of the endpoint
+	for _, endpoint := range config.Endpoints {
+		accessKey, secretKey := endpoint.Credentials(account)
+		if accessKey == "" || secretKey == "" {
+			log.Printf("AWS credentials of account %q for endpoint %s are not set in the environment variables.\n", account.Name, endpoint.Name)
+			continue
+		}
+		client, err := createS3ServiceClient(endpoint, accessKey, secretKey)
+		if err != nil {
+			fmt.Printf("Error creating service client for endpoint %s: %v\n", endpoint.Name, err)
+			continue
+		}
+		clients[endpoint.Name] = client
 	}
-	endpoints := map[string]EndpointConfig{
-		"eu-central-2": {
-			Region:    "eu-central-2",
-			AccessKey: accessKey,
-			SecretKey: secretKey,
-			Endpoint:  "https://s3-eu-central-2.ionoscloud.com",
-		},
-		"de": {
-			Region:    "de",
-			AccessKey: accessKey,
-			SecretKey: secretKey,
-			Endpoint:  "https://s3-eu-central-1.ionoscloud.com",
-		},
+	if len(clients) == 0 {
+		log.Printf("No S3 endpoint can be scraped for account %q.\n", account.Name)
+		return
 	}
 	semaphore := make(chan struct{}, maxConcurrent)
-	for {
+	usageSemaphore := make(chan struct{}, config.Usage.Concurrency)
+	config.Run("s3", account, func(ctx context.Context) {
 		var wg sync.WaitGroup
-		for _, endpoint := range endpoints {
-
-			if _, exists := IonosS3Buckets[endpoint.Endpoint]; exists {
-				continue
-			}
-			client, err := createS3ServiceClient(endpoint.Region, accessKey, secretKey, endpoint.Endpoint)
+		for endpointName, client := range clients {
+			fmt.Println("Using service client for endpoint:", endpointName)
 
-			if err != nil {
-				fmt.Printf("Error creating service client for endpoint %s: %v\n", endpoint, err)
-				continue
-			}
-			fmt.Println("Using service client for endpoint:", endpoint)
-
-			result, err := client.ListBuckets(nil)
+			result, err := client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
 
 			if err != nil {
 				fmt.Println("Error while Listing Buckets", err)
@@ -108,86 +174,247 @@ func S3CollectResources(m *sync.RWMutex, cycletime int32) {
 
 			for _, bucket := range result.Buckets {
 				bucketName := *bucket.Name
-				if _, exists := IonosS3Buckets[bucketName]; !exists {
-					metrics := Metrics{
-						Methods:       make(map[string]int32),
-						RequestSizes:  make(map[string]int64),
-						ResponseSizes: make(map[string]int64),
-						Regions:       "",
-					}
-					IonosS3Buckets[bucketName] = metrics
-				}
 				wg.Add(1)
 				fmt.Println("Processing Bucket: ", bucketName)
-				go func(client *s3.S3, bucketName string) {
+				go func(client *s3.S3, endpointName string, bucketName string) {
 					defer wg.Done()
 					defer func() {
 						if r := recover(); r != nil {
 							log.Printf("Recovered in goroutine: %v", r)
 						}
 					}()
-					if err := GetHeadBucket(client, bucketName); err != nil {
+					if err := GetHeadBucket(ctx, client, bucketName); err != nil {
 						if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == 403 {
 							return
 						}
 						log.Println("Error checking the bucket head:", err)
 						return
 					}
+					// ListBuckets returns the buckets of all regions, only the endpoint of the bucket's region gets here
+					key := S3BucketKey{Account: account.Name, Endpoint: endpointName, Bucket: bucketName}
+					metricsMutex.Lock()
+					if _, exists := IonosS3Buckets[key]; !exists {
+						IonosS3Buckets[key] = newMetrics(account.Name, endpointName)
+					}
+					metricsMutex.Unlock()
+					if config.Usage.Enabled && bucketUsageDue(key, config.Usage) {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							usageSemaphore <- struct{}{}
+							defer func() {
+								<-usageSemaphore
+							}()
+							collectBucketUsage(ctx, client, key)
+						}()
+					}
 					semaphore <- struct{}{}
 					defer func() {
 						<-semaphore
 					}()
-					processBucket(client, bucketName)
-				}(client, bucketName)
+					processBucket(ctx, client, key, config)
+				}(client, endpointName, bucketName)
 			}
 
 		}
 		wg.Wait()
-		time.Sleep(time.Duration(cycletime) * time.Second)
+		saveS3Checkpoints()
+	})
+}
+
+func processBucket(ctx context.Context, client *s3.S3, key S3BucketKey, config S3CollectorConfig) {
+	bucketName := key.Bucket
+
+	getBucketTags(ctx, client, key)
+
+	getAclInput := &s3.GetBucketAclInput{
+		Bucket: aws.String(bucketName),
+	}
+	getAclOutput, err := client.GetBucketAclWithContext(ctx, getAclInput)
+	if err != nil {
+		log.Printf("Error retrieving ACL for bucket %s: %v\n", bucketName, err)
+		return
+	}
+	owner := "Unknown"
+	if len(*getAclOutput.Owner.DisplayName) > 0 {
+		owner = *getAclOutput.Owner.DisplayName
+	}
+	if config.Posture {
+		posture := bucketPosture(ctx, client, bucketName, getAclOutput)
+		metricsMutex.Lock()
+		// Failed checks are missing from the result, they keep the value of the last successful check
+		for check, present := range IonosS3Posture[key] {
+			if _, checked := posture[check]; !checked {
+				posture[check] = present
+			}
+		}
+		IonosS3Posture[key] = posture
+		metricsMutex.Unlock()
+	}
+	if config.Multipart.Enabled {
+		uploads, err := fetchMultipartUploads(ctx, client, bucketName, config.Multipart.SampleSize)
+		if err != nil {
+			log.Printf("Error listing multipart uploads of bucket %s: %v\n", bucketName, err)
+		} else {
+			metricsMutex.Lock()
+			IonosS3Multipart[key] = uploads
+			metricsMutex.Unlock()
+		}
+	}
+
+	location := logLocation(ctx, client, bucketName, config.Logs)
+	checkpointKey := s3CheckpointKey(key.Account, key.Endpoint, bucketName)
+	// If the log location changed, the checkpoint belongs to the old location and all logs of the new one are read
+	startAfter := getS3Checkpoint(checkpointKey).startAfter(location)
+	objects, err := listLogObjects(ctx, client, location, startAfter, config.Logs.MaxObjects)
+	if err != nil {
+		return
 	}
 
+	metricsMutex.Lock()
+	bucketMetrics := IonosS3Buckets[key]
+	bucketMetrics.Regions = *client.Config.Region
+	bucketMetrics.Owner = owner
+	IonosS3Buckets[key] = bucketMetrics
+	metricsMutex.Unlock()
+
+	// The checkpoint advances after every batch, so only the Metrics of one batch are held in memory
+	for start := 0; start < len(objects); start += logBatchSize {
+		end := start + logBatchSize
+		if end > len(objects) {
+			end = len(objects)
+		}
+		if !processLogBatch(ctx, client, key, location, objects[start:end], checkpointKey, config.TopN) {
+			return
+		}
+	}
 }
 
-func processBucket(client *s3.S3, bucketName string) {
+/*
+Processes a batch of log objects in parallel and adds their metrics to the bucket in the order of the keys
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - key: the bucket the logs are about
+  - location: the bucket and prefix the logs are stored in
+  - objects: the log objects of the batch in the order of their keys
+  - checkpointKey: key of the checkpoint of the bucket
+  - topN: configuration of the traffic breakdowns
 
+Returns:
+  - bool: false if an object failed, it and all objects after it are retried in the next cycle
+*/
+func processLogBatch(ctx context.Context, client *s3.S3, key S3BucketKey, location s3LogLocation, objects []*s3.Object, checkpointKey string, topN S3TopNConfig) bool {
 	var wg sync.WaitGroup
-	logEntryRegex := regexp.MustCompile(`(GET|PUT|HEAD|POST) \/[^"]*" \d+ \S+ (\d+|-) (\d+|-) \d+ (\d+|-)`)
 	semaphore := make(chan struct{}, maxConcurrent)
 
-	getBucketTags(client, bucketName)
-	metrics := Metrics{
-		Methods:       make(map[string]int32),
+	// Every object is parsed into its own Metrics, so a failed object can be retried
+	// in the next cycle without counting the objects after it twice
+	objectMetrics := make([]*Metrics, len(objects))
+	for i, object := range objects {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, object *s3.Object) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			metrics := newMetrics(key.Account, key.Endpoint)
+			if err := processObject(ctx, client, location, object, &metrics, topN); err == nil {
+				objectMetrics[i] = &metrics
+			}
+		}(i, object)
+	}
+	wg.Wait()
+
+	complete := true
+	lastKey := ""
+	metricsMutex.Lock()
+	bucketMetrics := IonosS3Buckets[key]
+	for i, metrics := range objectMetrics {
+		if metrics == nil {
+			log.Printf("Stopping at log object %s of bucket %s, it is retried in the next cycle\n", *objects[i].Key, location.Bucket)
+			complete = false
+			break
+		}
+		bucketMetrics.add(metrics)
+		lastKey = *objects[i].Key
+	}
+	IonosS3Buckets[key] = bucketMetrics
+	metricsMutex.Unlock()
+	if lastKey != "" {
+		setS3Checkpoint(checkpointKey, location, lastKey)
+	}
+	return complete
+}
+
+func newMetrics(account string, endpoint string) Metrics {
+	return Metrics{
+		Methods:       make(map[string]int64),
 		RequestSizes:  make(map[string]int64),
 		ResponseSizes: make(map[string]int64),
-		Regions:       "",
-		Owner:         "",
-	}
-	metrics.Regions = *client.Config.Region
+		Account:       account,
+		Endpoint:      endpoint,
 
-	continuationToken := ""
+		Operations:      make(map[S3OperationKey]int64),
+		TotalTimes:      make(map[string]*LatencyHistogram),
+		TurnAroundTimes: make(map[string]*LatencyHistogram),
 
-	getAclInput := &s3.GetBucketAclInput{
-		Bucket: aws.String(bucketName),
+		Requesters:     make(map[string]*S3Traffic),
+		RemoteNetworks: make(map[string]*S3Traffic),
+		KeyPrefixes:    make(map[string]*S3Traffic),
 	}
-	getAclOutput, err := client.GetBucketAcl(getAclInput)
-	if err != nil {
-		log.Printf("Error retrieving ACL for bucket %s: %v\n", bucketName, err)
-		return
+}
+
+// Adds the counts of other to the counts of metrics
+func (metrics *Metrics) add(other *Metrics) {
+	for method, count := range other.Methods {
+		metrics.Methods[method] += count
 	}
-	if len(*getAclOutput.Owner.DisplayName) > 0 {
-		metrics.Owner = *getAclOutput.Owner.DisplayName
-	} else {
-		metrics.Owner = "Unknown"
+	for method, size := range other.RequestSizes {
+		metrics.RequestSizes[method] += size
+	}
+	for method, size := range other.ResponseSizes {
+		metrics.ResponseSizes[method] += size
 	}
+	for key, count := range other.Operations {
+		metrics.Operations[key] += count
+	}
+	for method, histogram := range other.TotalTimes {
+		latencyHistogram(metrics.TotalTimes, method).add(histogram)
+	}
+	for method, histogram := range other.TurnAroundTimes {
+		latencyHistogram(metrics.TurnAroundTimes, method).add(histogram)
+	}
+	metrics.ParseErrors += other.ParseErrors
+	mergeTraffic(metrics.Requesters, other.Requesters)
+	mergeTraffic(metrics.RemoteNetworks, other.RemoteNetworks)
+	mergeTraffic(metrics.KeyPrefixes, other.KeyPrefixes)
+}
 
-	for {
+/*
+Lists the log objects of a bucket which were written after the checkpoint
 
-		objectList, err := client.ListObjectsV2(&s3.ListObjectsV2Input{
-			Bucket:            aws.String(bucketName),
-			Prefix:            aws.String("logs/"),
-			ContinuationToken: aws.String(continuationToken),
-			MaxKeys:           aws.Int64(objectPerPage),
-		})
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - location: the bucket and prefix the logs are stored in
+  - startAfter: key of the last processed log object, empty to list all log objects
+  - limit: maximum number of log objects to return, 0 for no limit
+
+Returns:
+  - []*s3.Object: the new log objects in the order of their keys, or an error if the listing failed
+*/
+func listLogObjects(ctx context.Context, client *s3.S3, location s3LogLocation, startAfter string, limit int) ([]*s3.Object, error) {
+	bucketName := location.Bucket
+	var objects []*s3.Object
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(bucketName),
+		Prefix:     aws.String(location.Prefix),
+		StartAfter: aws.String(startAfter),
+		MaxKeys:    aws.Int64(objectPerPage),
+	}
+	for {
+		objectList, err := client.ListObjectsV2WithContext(ctx, input)
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); ok {
 				switch aerr.Code() {
@@ -202,34 +429,69 @@ func processBucket(client *s3.S3, bucketName string) {
 					fmt.Printf("error listing objects in bucket %s: %s\n", bucketName, aerr.Message())
 				}
 			}
-			return
-		}
-		if len(objectList.Contents) == 0 {
-			log.Printf("bucket %s does not contain any objects with the 'logs/' prefix\n", bucketName)
-			return
+			return nil, err
 		}
-		for _, object := range objectList.Contents {
-			wg.Add(1)
-			semaphore <- struct{}{}
-			go func(object *s3.Object) {
-				defer wg.Done()
-				defer func() { <-semaphore }()
-				processObject(client, bucketName, object, logEntryRegex, &metrics)
-			}(object)
+		objects = append(objects, objectList.Contents...)
+		if limit > 0 && len(objects) >= limit {
+			// The rest is listed in the next cycle after the checkpoint
+			objects = objects[:limit]
+			break
 		}
 		if !aws.BoolValue(objectList.IsTruncated) {
 			break
 		}
-		continuationToken = *objectList.NextContinuationToken
+		input.ContinuationToken = objectList.NextContinuationToken
 	}
-	wg.Wait()
-	metricsMutex.Lock()
-	IonosS3Buckets[bucketName] = metrics
-	metricsMutex.Unlock()
+	if len(objects) == 0 {
+		log.Printf("bucket %s does not contain any new objects with the '%s' prefix\n", bucketName, location.Prefix)
+	}
+	return objects, nil
+}
+
+// Location of the access logs of a bucket
+type s3LogLocation struct {
+	SourceBucket string // Bucket the logs are about, the metrics are attributed to it
+	Bucket       string // Bucket the logs are stored in
+	Prefix       string
+}
+
+/*
+Finds out where the access logs of a bucket are stored. A configured target takes precedence over the
+logging configuration of the bucket, without both the logs are expected in the bucket itself.
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - bucketName: name of the source bucket
+  - config: log configuration of the S3 collector
+
+Returns:
+  - s3LogLocation: bucket and prefix to read the logs from
+*/
+func logLocation(ctx context.Context, client *s3.S3, bucketName string, config S3LogsConfig) s3LogLocation {
+	if target, exists := config.Targets[bucketName]; exists {
+		return s3LogLocation{SourceBucket: bucketName, Bucket: target.Bucket, Prefix: target.Prefix}
+	}
+	if config.BucketLogging {
+		logging, err := client.GetBucketLoggingWithContext(ctx, &s3.GetBucketLoggingInput{
+			Bucket: aws.String(bucketName),
+		})
+		if err != nil {
+			log.Printf("Error retrieving logging configuration of bucket %s, using the bucket itself: %v\n", bucketName, err)
+		} else if logging.LoggingEnabled != nil && aws.StringValue(logging.LoggingEnabled.TargetBucket) != "" {
+			return s3LogLocation{
+				SourceBucket: bucketName,
+				Bucket:       aws.StringValue(logging.LoggingEnabled.TargetBucket),
+				Prefix:       aws.StringValue(logging.LoggingEnabled.TargetPrefix),
+			}
+		}
+	}
+	return s3LogLocation{SourceBucket: bucketName, Bucket: bucketName, Prefix: config.Prefix}
 }
 
-func getBucketTags(client *s3.S3, bucketName string) {
-	tagsOutput, err := client.GetBucketTagging(&s3.GetBucketTaggingInput{
+func getBucketTags(ctx context.Context, client *s3.S3, key S3BucketKey) {
+	bucketName := key.Bucket
+	tagsOutput, err := client.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
 		Bucket: aws.String(bucketName),
 	})
 
@@ -257,60 +519,75 @@ func getBucketTags(client *s3.S3, bucketName string) {
 	}
 
 	metricsMutex.Lock()
-	TagsForPrometheus[bucketName] = tags
+	TagsForPrometheus[key] = tags
 	metricsMutex.Unlock()
 }
 
-func processObject(client *s3.S3, bucketName string, object *s3.Object, logEntryRegex *regexp.Regexp, metrics *Metrics) {
+// Parses one log object into metrics, an error is returned if the object could not be read completely
+func processObject(ctx context.Context, client *s3.S3, location s3LogLocation, object *s3.Object, metrics *Metrics, topN S3TopNConfig) error {
+	bucketName := location.Bucket
 	downloadInput := &s3.GetObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(*object.Key),
 	}
-	result, err := client.GetObject(downloadInput)
+	result, err := client.GetObjectWithContext(ctx, downloadInput)
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "AccessDenied" {
 			log.Printf("Access Denied error for object %s in bucket %s\n", *object.Key, bucketName)
-			return
+			return err
 		}
 		log.Println("Error downloading object", err)
-		return
+		return err
 	}
 	defer result.Body.Close()
 
 	reader := bufio.NewReader(result.Body)
 	for {
 		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			processLine(line, location.SourceBucket, metrics, topN)
+		}
 		if err != nil {
 			if err != io.EOF {
 				log.Println("Problem reading the body", err)
+				return err
 			}
-			break
+			return nil
 		}
-		processLine(line, logEntryRegex, metrics)
 	}
 }
 
-func processLine(line []byte, logEntryRegex *regexp.Regexp, metrics *Metrics) {
-	matches := logEntryRegex.FindAllStringSubmatch(string(line), -1)
-	for _, match := range matches {
-		metricsMutex.Lock()
-		method := match[1]
-		requestSizeStr := match[3]
-		responseSizeStr := match[2]
-
-		if requestSizeStr != "-" {
-			requestSize, err := strconv.ParseInt(requestSizeStr, 10, 64)
-			if err == nil {
-				metrics.RequestSizes[method] += requestSize
-			}
-		}
-		if responseSizeStr != "-" {
-			responseSize, err := strconv.ParseInt(responseSizeStr, 10, 64)
-			if err == nil {
-				metrics.ResponseSizes[method] += responseSize
-			}
+func processLine(line []byte, sourceBucket string, metrics *Metrics, topN S3TopNConfig) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	entry, err := parseS3LogLine(string(line))
+	if err != nil {
+		metrics.ParseErrors++
+		return
+	}
+	// Several source buckets may log to the same target prefix
+	if entry.Bucket != "" && entry.Bucket != sourceBucket {
+		return
+	}
+	metrics.Operations[S3OperationKey{Operation: entry.Operation, StatusClass: entry.StatusClass()}]++
+	// Requests S3 does on its own, e.g. lifecycle expirations, have no request URI and no timings
+	if entry.Method == "" {
+		return
+	}
+	metrics.Methods[entry.Method]++
+	metrics.RequestSizes[entry.Method] += entry.ObjectSize
+	metrics.ResponseSizes[entry.Method] += entry.BytesSent
+	latencyHistogram(metrics.TotalTimes, entry.Method).observe(entry.TotalTime.Seconds())
+	latencyHistogram(metrics.TurnAroundTimes, entry.Method).observe(entry.TurnAroundTime.Seconds())
+
+	if topN.Limit > 0 {
+		requester := entry.Requester
+		if requester == "" {
+			requester = "anonymous"
 		}
-		metrics.Methods[method]++
-		metricsMutex.Unlock()
+		trafficOf(metrics.Requesters, requester).add(entry)
+		trafficOf(metrics.RemoteNetworks, remoteNetwork(entry.RemoteIp, topN)).add(entry)
+		trafficOf(metrics.KeyPrefixes, keyPrefix(entry.Key, topN.KeyPrefixDepth)).add(entry)
 	}
 }
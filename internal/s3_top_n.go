@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// Name of the entry the traffic outside of the top N is summed up in
+	s3OtherTraffic = "other"
+	// Maximum number of entries kept per breakdown and bucket, the entries with the least
+	// bytes sent are folded into other, so the memory stays bounded for buckets with many clients
+	s3MaxTrafficEntries = 1000
+)
+
+type namedTraffic struct {
+	Name string
+	S3Traffic
+}
+
+func (traffic *S3Traffic) add(entry s3LogEntry) {
+	traffic.Requests++
+	traffic.BytesSent += entry.BytesSent
+}
+
+// Returns the traffic of the name, it is created if it does not exist yet
+func trafficOf(breakdown map[string]*S3Traffic, name string) *S3Traffic {
+	if _, exists := breakdown[name]; !exists {
+		breakdown[name] = &S3Traffic{}
+	}
+	return breakdown[name]
+}
+
+// Traffic of the breakdown sorted by bytes sent, other is not part of the result
+func sortedTraffic(breakdown map[string]*S3Traffic) []namedTraffic {
+	sorted := make([]namedTraffic, 0, len(breakdown))
+	for name, traffic := range breakdown {
+		if name != s3OtherTraffic {
+			sorted = append(sorted, namedTraffic{Name: name, S3Traffic: *traffic})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BytesSent != sorted[j].BytesSent {
+			return sorted[i].BytesSent > sorted[j].BytesSent
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// Adds the traffic of other to the breakdown and keeps the breakdown bounded
+func mergeTraffic(breakdown map[string]*S3Traffic, other map[string]*S3Traffic) {
+	for name, traffic := range other {
+		sum := trafficOf(breakdown, name)
+		sum.Requests += traffic.Requests
+		sum.BytesSent += traffic.BytesSent
+	}
+	compactTraffic(breakdown)
+}
+
+// Folds the smallest entries into other if the breakdown has more than s3MaxTrafficEntries entries
+func compactTraffic(breakdown map[string]*S3Traffic) {
+	if len(breakdown) <= s3MaxTrafficEntries {
+		return
+	}
+	sorted := sortedTraffic(breakdown)
+	other := trafficOf(breakdown, s3OtherTraffic)
+	for _, traffic := range sorted[s3MaxTrafficEntries-1:] {
+		other.Requests += traffic.Requests
+		other.BytesSent += traffic.BytesSent
+		delete(breakdown, traffic.Name)
+	}
+}
+
+/*
+Selects the entries with the most bytes sent
+
+Parameters:
+  - breakdown: traffic per requester, network or key prefix
+  - limit: number of entries to return
+
+Returns:
+  - []namedTraffic: at most limit entries, followed by other with the sum of the remaining traffic if there is any
+*/
+func topTraffic(breakdown map[string]*S3Traffic, limit int) []namedTraffic {
+	sorted := sortedTraffic(breakdown)
+	if len(sorted) <= limit && breakdown[s3OtherTraffic] == nil {
+		return sorted
+	}
+	other := namedTraffic{Name: s3OtherTraffic}
+	if traffic, exists := breakdown[s3OtherTraffic]; exists {
+		other.S3Traffic = *traffic
+	}
+	if len(sorted) > limit {
+		for _, traffic := range sorted[limit:] {
+			other.Requests += traffic.Requests
+			other.BytesSent += traffic.BytesSent
+		}
+		sorted = sorted[:limit]
+	}
+	return append(sorted, other)
+}
+
+// Network of the remote IP in CIDR notation, unknown if the log has no valid IP
+func remoteNetwork(remoteIp string, topN S3TopNConfig) string {
+	ip := net.ParseIP(remoteIp)
+	if ip == nil {
+		return "unknown"
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		network := net.IPNet{IP: ipv4.Mask(net.CIDRMask(topN.IPv4PrefixLength, 32)), Mask: net.CIDRMask(topN.IPv4PrefixLength, 32)}
+		return network.String()
+	}
+	network := net.IPNet{IP: ip.Mask(net.CIDRMask(topN.IPv6PrefixLength, 128)), Mask: net.CIDRMask(topN.IPv6PrefixLength, 128)}
+	return network.String()
+}
+
+// Leading path segments of the object key, e.g. images/ for images/2024/logo.png and depth 1.
+// The name of the object itself is never part of the prefix, keys without a path are grouped as /.
+func keyPrefix(key string, depth int) string {
+	segments := strings.Split(key, "/")
+	if len(segments)-1 < depth {
+		depth = len(segments) - 1
+	}
+	if depth == 0 {
+		return "/"
+	}
+	return strings.Join(segments[:depth], "/") + "/"
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"time"
+
+	aws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	IonosS3Usage = make(map[S3BucketKey]S3BucketUsage) // Guarded by metricsMutex
+)
+
+// Storage usage of a bucket, for versioned buckets all versions are included
+type S3BucketUsage struct {
+	Bytes      map[string]int64 // Size of the objects in bytes per storage class
+	Objects    map[string]int64 // Number of objects per storage class
+	LastListed time.Time        // Time of the listing the usage is based on
+}
+
+// Reports whether the usage of the bucket was not listed within the sampling interval
+func bucketUsageDue(key S3BucketKey, config S3UsageConfig) bool {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	usage, exists := IonosS3Usage[key]
+	return !exists || time.Since(usage.LastListed) >= time.Duration(config.Interval)*time.Second
+}
+
+/*
+Lists all objects of a bucket and sums up their size and number per storage class.
+If versioning is enabled or suspended all versions are listed, delete markers are not counted.
+
+Parameters:
+  - ctx: context of the collection run, cancelled on timeout
+  - client: S3 client of the endpoint the bucket belongs to
+  - key: the bucket to list
+*/
+func collectBucketUsage(ctx context.Context, client *s3.S3, key S3BucketKey) {
+	bucketName := key.Bucket
+	usage := S3BucketUsage{
+		Bytes:   make(map[string]int64),
+		Objects: make(map[string]int64),
+	}
+	add := func(storageClass *string, size *int64) {
+		class := aws.StringValue(storageClass)
+		if class == "" {
+			class = s3.StorageClassStandard
+		}
+		usage.Bytes[class] += aws.Int64Value(size)
+		usage.Objects[class]++
+	}
+
+	versioning, err := client.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.Printf("Error retrieving versioning of bucket %s: %v\n", bucketName, err)
+		return
+	}
+	if aws.StringValue(versioning.Status) != "" {
+		err = client.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+			Bucket:  aws.String(bucketName),
+			MaxKeys: aws.Int64(objectPerPage),
+		}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+			for _, version := range page.Versions {
+				add(version.StorageClass, version.Size)
+			}
+			return true
+		})
+	} else {
+		err = client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket:  aws.String(bucketName),
+			MaxKeys: aws.Int64(objectPerPage),
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range page.Contents {
+				add(object.StorageClass, object.Size)
+			}
+			return true
+		})
+	}
+	if err != nil {
+		log.Printf("Error listing the objects of bucket %s: %v\n", bucketName, err)
+		return
+	}
+
+	usage.LastListed = time.Now()
+	metricsMutex.Lock()
+	IonosS3Usage[key] = usage
+	metricsMutex.Unlock()
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+/*
+Runs a collector forever. Every run gets a context which is cancelled after the timeout of the collector,
+between two runs the collector sleeps for its cycle time plus a random jitter, so collectors and accounts
+with the same cycle time do not query the API at the same moment.
+
+Parameters:
+  - collector: name of the collector, used in log messages
+  - account: the account the collector scrapes
+  - collect: function doing one collection run, it should stop as soon as the context is done
+*/
+func (config CollectorConfig) Run(collector string, account AccountConfig, collect func(ctx context.Context)) {
+	for {
+		ctx, cancel := config.runContext()
+		collect(ctx)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "Collection run of %s for account %q exceeded the timeout of %ds\n", collector, account.Name, config.Timeout)
+		}
+		cancel()
+		time.Sleep(config.nextRun())
+	}
+}
+
+func (config CollectorConfig) runContext() (context.Context, context.CancelFunc) {
+	if config.Timeout > 0 {
+		return context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// Time to wait until the next run, the cycle time plus a random jitter
+func (config CollectorConfig) nextRun() time.Duration {
+	wait := time.Duration(config.Cycle) * time.Second
+	if config.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(config.Jitter) * int64(time.Second)))
+	}
+	return wait
+}
@@ -5,7 +5,6 @@ import (
 	"ionos-exporter/internal"
 	"log"
 	"net/http"
-	"strconv"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -14,9 +13,8 @@ import (
 )
 
 var (
-	m               = &sync.RWMutex{} // Mutex to sync access to the Datacenter map
-	exporterPort    string            // Port to be used for exposing the metrics
-	ionos_api_cycle int32             // Cycle time in seconds to query the IONOS API for changes, not th ePrometheus scraping intervall
+	m            = &sync.RWMutex{} // Mutex to sync access to the Datacenter map
+	exporterPort string            // Port to be used for exposing the metrics
 )
 
 func main() {
@@ -30,31 +28,49 @@ func main() {
 		}
 	}
 
-	exporterPort = internal.GetEnv("IONOS_EXPORTER_APPLICATION_CONTAINER_PORT", "9100")
-	if cycletime, err := strconv.ParseInt(internal.GetEnv("IONOS_EXPORTER_API_CYCLE", "200"), 10, 32); err != nil {
-		log.Fatal("Cannot convert IONOS_API_CYCLE to int")
-	} else {
-		ionos_api_cycle = int32(cycletime)
+	config := internal.Must(internal.LoadConfig(*configPath))
+	exporterPort = config.Port
+	collectors := config.Collectors
+	if collectors.S3.Enabled {
+		if err := internal.LoadS3Checkpoints(collectors.S3); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, account := range config.Accounts {
+		if collectors.Datacenter.Enabled {
+			go internal.CollectResources(m, account, collectors.Datacenter)
+		}
+		if collectors.S3.Enabled {
+			go internal.S3CollectResources(m, account, collectors.S3)
+		}
+		if collectors.Postgres.Enabled {
+			go internal.PostgresCollectResources(m, account, collectors.Postgres, config.Metrics)
+		}
+		if collectors.Storage.Enabled {
+			go internal.StorageCollectResources(m, account, collectors.Storage)
+		}
+		if collectors.K8s.Enabled {
+			go internal.K8sCollectResources(m, account, collectors.K8s)
+		}
+		if collectors.Connectivity.Enabled {
+			go internal.ConnectivityCollectResources(m, account, collectors.Connectivity)
+		}
+		if collectors.Requests.Enabled {
+			go internal.RequestsCollectResources(m, account, collectors.Requests)
+		}
 	}
-	go internal.CollectResources(m, ionos_api_cycle)
 
 	// Contract Limits Exporter
-	if internal.Must(internal.GetBoolEnv("IONOS_EXPORTER_CONTRACT_LIMITS_ENABLED", true)) {
+	if collectors.ContractLimits.Enabled {
 		contractLimitsCollector := internal.NewContractLimitsCollector()
-		go contractLimitsCollector.StartScrape(ionos_api_cycle)
+		for _, account := range config.Accounts {
+			go contractLimitsCollector.StartScrape(account, collectors.ContractLimits)
+		}
 		prometheus.MustRegister(contractLimitsCollector)
 	}
 
-	if internal.Must(internal.GetBoolEnv("IONOS_EXPORTER_S3_ENABLED", false)) {
-		go internal.S3CollectResources(m, ionos_api_cycle)
-	}
-
-	if internal.Must(internal.GetBoolEnv("IONOS_EXPORTER_POSTGRES_ENABLED", false)) {
-		go internal.PostgresCollectResources(m, *configPath, ionos_api_cycle)
-	}
-
 	internal.PrintDCResources(m)
-	internal.StartPrometheus(m)
+	internal.StartPrometheus(m, config)
 	http.Handle("/metrics", promhttp.Handler())
 	http.Handle("/healthcheck", http.HandlerFunc(internal.HealthCheck))
 	log.Fatal(http.ListenAndServe(":"+exporterPort, nil))